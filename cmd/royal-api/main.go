@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/leopoldhub/royal-api-personal/internal/api"
+	"github.com/leopoldhub/royal-api-personal/internal/collector"
+	"github.com/leopoldhub/royal-api-personal/internal/config"
+	"github.com/leopoldhub/royal-api-personal/internal/database"
+	"github.com/leopoldhub/royal-api-personal/internal/database/repository"
+	"github.com/leopoldhub/royal-api-personal/internal/metrics"
+	"github.com/leopoldhub/royal-api-personal/pkg/supercell"
+)
+
+const usage = `usage: royal-api <command> [args]
+
+commands:
+  serve                      start the REST API
+  collect [--resume|--fresh] run one collection, resuming an interrupted one by default
+  collect-loop               fetch due players every COLLECT_TICK_MINUTES until stopped,
+                             serving /metrics on METRICS_PORT
+  reparse                    re-parse the raw battlelog archive and recalculate meta decks
+                             and the daily history of the replaced days
+  migrate status|up|down [--steps N]
+
+The command may also be given as -command <command> (Docker image).`
+
+func main() {
+	logger := log.New(os.Stdout, "", log.LstdFlags)
+
+	command, args := parseCommand(os.Args[1:])
+	if command == "" || command == "help" || command == "-h" || command == "--help" {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := run(ctx, command, args, logger)
+	stop()
+
+	if err != nil {
+		logger.Fatalf("%s: %v", command, err)
+	}
+}
+
+// parseCommand accepts both "royal-api serve" and "royal-api -command serve"
+func parseCommand(args []string) (string, []string) {
+	if len(args) == 0 {
+		return "", nil
+	}
+	if (args[0] == "-command" || args[0] == "--command") && len(args) > 1 {
+		return args[1], args[2:]
+	}
+	return args[0], args[1:]
+}
+
+func run(ctx context.Context, command string, args []string, logger *log.Logger) error {
+	cfg, err := config.LoadFromEnv()
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	db, err := database.Connect(cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close(db)
+
+	if command == "migrate" {
+		return database.MigrateCommand(ctx, db, cfg.MigrationsPath, args, os.Stdout)
+	}
+
+	if err := database.RunMigrations(db, cfg.MigrationsPath); err != nil {
+		return fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	switch command {
+	case "serve":
+		server := api.NewServer(db, cfg.APIToken, cfg.RateLimits(), logger)
+		return server.Start(ctx, cfg.APIPort)
+
+	case "collect":
+		opts, err := collector.ParseCollectArgs(args)
+		if err != nil {
+			return err
+		}
+		service, err := newCollector(cfg, db, cfg.ArchiveRaw, logger)
+		if err != nil {
+			return err
+		}
+		result, err := service.CollectWithOptions(ctx, opts)
+		if err != nil {
+			return err
+		}
+		logger.Printf("Collection: %d players, %d battles stored, %d errors in %v",
+			result.PlayersProcessed, result.BattlesStored, len(result.Errors), result.Duration)
+		return nil
+
+	case "collect-loop":
+		service, err := newCollector(cfg, db, cfg.ArchiveRaw, logger)
+		if err != nil {
+			return err
+		}
+		return collectLoop(ctx, cfg, db, service, logger)
+
+	case "reparse":
+		// The archive is read even when ARCHIVE_RAW no longer records new battlelogs
+		service, err := newCollector(cfg, db, true, logger)
+		if err != nil {
+			return err
+		}
+		result, err := service.Reparse(ctx)
+		if err != nil {
+			return err
+		}
+		logger.Printf("Reparse: %d battlelogs, %d battles stored, %d replaced in %v",
+			result.Battlelogs, result.BattlesStored, result.BattlesDeleted, result.Duration)
+		return nil
+
+	default:
+		return fmt.Errorf("unknown command %q\n%s", command, usage)
+	}
+}
+
+// collectLoop runs the scheduler until ctx is canceled, serving the Supercell,
+// collector and database pool metrics on METRICS_PORT meanwhile. The loop stops
+// when the metrics server fails.
+func collectLoop(ctx context.Context, cfg *config.Config, db *sql.DB, service collector.Service, logger *log.Logger) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	serveErr := make(chan error, 1)
+	if cfg.MetricsPort > 0 {
+		registry := metrics.NewRegistry()
+		metrics.RegisterDBStats(registry, db)
+
+		addr := fmt.Sprintf(":%d", cfg.MetricsPort)
+		logger.Printf("Serving collector metrics on %s/metrics", addr)
+		go func() {
+			err := metrics.Serve(ctx, addr, registry, metrics.Default)
+			cancel()
+			serveErr <- err
+		}()
+	} else {
+		serveErr <- nil
+	}
+
+	tick := time.Duration(cfg.CollectTick) * time.Minute
+	err := collector.NewScheduler(service, tick, logger).Run(ctx)
+	cancel()
+
+	if err := <-serveErr; err != nil {
+		return err
+	}
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+// newSupercellClient creates the client of SUPERCELL_SOURCE, with the response
+// cache of SUPERCELL_CACHE for the HTTP sources
+func newSupercellClient(cfg *config.Config) (supercell.Client, error) {
+	cache, err := supercell.NewCache(cfg.SupercellCache, cfg.CacheDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return supercell.New(supercell.Options{
+		Source:      cfg.SupercellSource,
+		APIKey:      cfg.SupercellAPIKey,
+		BaseURL:     cfg.SupercellBaseURL,
+		FixturesDir: cfg.FixturesDir,
+		Cache:       cache,
+	})
+}
+
+// newCollector creates the collector service, archive enabling the raw battlelog archive
+func newCollector(cfg *config.Config, db *sql.DB, archive bool, logger *log.Logger) (collector.Service, error) {
+	client, err := newSupercellClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var archiveRepo repository.RawBattlelogRepository
+	if archive {
+		archiveRepo = repository.NewRawBattlelogRepository(db)
+	}
+
+	return collector.NewService(
+		client,
+		repository.NewBattleRepository(db),
+		repository.NewMetaDeckRepository(db),
+		repository.NewPlayerScheduleRepository(db),
+		repository.NewCollectionStatsRepository(db),
+		repository.NewRejectedBattleRepository(db),
+		archiveRepo,
+		repository.NewMetaHistoryRepository(db),
+		cfg.TopPlayersLimit,
+		cfg.RetentionDays,
+		logger,
+	), nil
+}
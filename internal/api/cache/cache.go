@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+)
+
+// Entry is a cached response body for a data version
+type Entry struct {
+	Version     int64
+	Body        []byte
+	ContentType string
+	ETag        string
+}
+
+// NewEntry creates an entry with a strong ETag derived from version and body
+func NewEntry(version int64, body []byte, contentType string) *Entry {
+	sum := sha256.Sum256(body)
+	return &Entry{
+		Version:     version,
+		Body:        body,
+		ContentType: contentType,
+		ETag:        `"` + hex.EncodeToString(sum[:8]) + `"`,
+	}
+}
+
+// Store is an in-process response cache, entries of an older data version
+// being treated as missing
+type Store struct {
+	mu         sync.Mutex
+	entries    map[string]*Entry
+	maxEntries int
+}
+
+// NewStore creates a store holding at most maxEntries responses
+func NewStore(maxEntries int) *Store {
+	return &Store{
+		entries:    make(map[string]*Entry),
+		maxEntries: maxEntries,
+	}
+}
+
+// Get returns the entry of key cached for version
+func (s *Store) Get(key string, version int64) (*Entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || entry.Version != version {
+		return nil, false
+	}
+	return entry, true
+}
+
+// Set caches entry under key. When the store is full, entries of older
+// versions are evicted first, then everything.
+func (s *Store) Set(key string, entry *Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.entries[key]; !ok && len(s.entries) >= s.maxEntries {
+		for k, cached := range s.entries {
+			if cached.Version != entry.Version {
+				delete(s.entries, k)
+			}
+		}
+		if len(s.entries) >= s.maxEntries {
+			clear(s.entries)
+		}
+	}
+	s.entries[key] = entry
+}
+
+// Len returns the number of cached entries
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
@@ -0,0 +1,47 @@
+package cache
+
+import "testing"
+
+func TestStore_VersionInvalidates(t *testing.T) {
+	store := NewStore(10)
+	store.Set("/decks/meta?", NewEntry(1, []byte(`{"decks":[]}`), "application/json"))
+
+	if _, ok := store.Get("/decks/meta?", 1); !ok {
+		t.Fatal("entry missing for its version")
+	}
+	if _, ok := store.Get("/decks/meta?", 2); ok {
+		t.Error("entry of version 1 served for version 2")
+	}
+}
+
+func TestStore_EvictsOlderVersionsFirst(t *testing.T) {
+	store := NewStore(2)
+	store.Set("a", NewEntry(1, []byte("a"), ""))
+	store.Set("b", NewEntry(2, []byte("b"), ""))
+	store.Set("c", NewEntry(2, []byte("c"), ""))
+
+	if store.Len() != 2 {
+		t.Fatalf("len = %d, want 2", store.Len())
+	}
+	if _, ok := store.Get("b", 2); !ok {
+		t.Error("current version entry evicted")
+	}
+
+	store.Set("d", NewEntry(2, []byte("d"), ""))
+	if store.Len() > 2 {
+		t.Errorf("len = %d, want at most 2", store.Len())
+	}
+}
+
+func TestNewEntry_ETag(t *testing.T) {
+	a := NewEntry(1, []byte("a"), "")
+	if a.ETag != NewEntry(1, []byte("a"), "").ETag {
+		t.Error("same body produced different ETags")
+	}
+	if a.ETag == NewEntry(1, []byte("b"), "").ETag {
+		t.Error("different bodies share an ETag")
+	}
+	if a.ETag[0] != '"' || a.ETag[len(a.ETag)-1] != '"' {
+		t.Errorf("ETag %s is not quoted", a.ETag)
+	}
+}
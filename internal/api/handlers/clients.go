@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/leopoldhub/royal-api-personal/internal/api/respond"
+	"github.com/leopoldhub/royal-api-personal/internal/auth"
+	"github.com/leopoldhub/royal-api-personal/internal/database/repository"
+	"github.com/leopoldhub/royal-api-personal/internal/errors"
+	"github.com/leopoldhub/royal-api-personal/internal/models"
+)
+
+// ClientHandler manages the API clients
+type ClientHandler struct {
+	clientRepo repository.APIClientRepository
+}
+
+// NewClientHandler creates a new API client handler
+func NewClientHandler(clientRepo repository.APIClientRepository) *ClientHandler {
+	return &ClientHandler{
+		clientRepo: clientRepo,
+	}
+}
+
+type clientsResponse struct {
+	Clients []*models.APIClient `json:"clients"`
+}
+
+type createClientRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"` // omitted for a token that never expires
+}
+
+type createClientResponse struct {
+	Client *models.APIClient `json:"client"`
+	Token  string            `json:"token"` // only returned once, stored as a hash
+}
+
+// ListClients handles GET /clients
+func (h *ClientHandler) ListClients(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	clients, err := h.clientRepo.List(ctx)
+	if err != nil {
+		respond.Failure(w, r, err, "failed to fetch clients")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(clientsResponse{Clients: clients})
+}
+
+// CreateClient handles POST /clients
+func (h *ClientHandler) CreateClient(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req createClientRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respond.Error(w, r, http.StatusBadRequest, respond.CodeInvalidBody, "invalid JSON body")
+		return
+	}
+	if invalid := validateClient(&req); invalid != nil {
+		respond.Invalid(w, r, invalid)
+		return
+	}
+
+	token, err := auth.GenerateToken()
+	if err != nil {
+		respond.Failure(w, r, err, "failed to generate token")
+		return
+	}
+
+	client := &models.APIClient{
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+	}
+	if err := h.clientRepo.Create(ctx, client, auth.HashToken(token)); err != nil {
+		var invalid *errors.ValidationError
+		if stderrors.As(err, &invalid) {
+			respond.Invalid(w, r, invalid)
+			return
+		}
+		respond.Failure(w, r, err, "failed to create client")
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(createClientResponse{Client: client, Token: token})
+}
+
+// RevokeClient handles DELETE /clients/{id}
+func (h *ClientHandler) RevokeClient(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, invalid := pathInt(r, "id")
+	if invalid != nil {
+		respond.Invalid(w, r, invalid)
+		return
+	}
+
+	revoked, err := h.clientRepo.Revoke(ctx, id)
+	if err != nil {
+		respond.Failure(w, r, err, "failed to revoke client")
+		return
+	}
+	if !revoked {
+		respond.Error(w, r, http.StatusNotFound, respond.CodeNotFound, "client not found or already revoked")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func validateClient(req *createClientRequest) *errors.ValidationError {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > 100 {
+		return &errors.ValidationError{Field: "name", Message: "must be 1 to 100 characters"}
+	}
+	if req.Name == auth.BootstrapClient {
+		return &errors.ValidationError{Field: "name", Message: "reserved for API_TOKEN"}
+	}
+	if len(req.Scopes) == 0 {
+		return &errors.ValidationError{Field: "scopes", Message: "required"}
+	}
+	for _, scope := range req.Scopes {
+		if !slices.Contains(models.Scopes, scope) {
+			return &errors.ValidationError{Field: "scopes", Message: "must be among " + strings.Join(models.Scopes, ", ")}
+		}
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return &errors.ValidationError{Field: "expires_at", Message: "must be in the future"}
+	}
+	return nil
+}
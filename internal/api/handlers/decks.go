@@ -1,33 +1,41 @@
 package handlers
 
 import (
-	"context"
 	"encoding/json"
 	"net/http"
-	"strconv"
 	"time"
 
+	"github.com/leopoldhub/royal-api-personal/internal/api/respond"
 	"github.com/leopoldhub/royal-api-personal/internal/database/repository"
+	"github.com/leopoldhub/royal-api-personal/internal/errors"
 	"github.com/leopoldhub/royal-api-personal/internal/models"
+	"github.com/leopoldhub/royal-api-personal/pkg/utils"
 )
 
 // DeckHandler handles deck-related requests
 type DeckHandler struct {
-	battleRepo repository.BattleRepository
-	metaRepo   repository.MetaDeckRepository
+	battleRepo  repository.BattleRepository
+	metaRepo    repository.MetaDeckRepository
+	historyRepo repository.MetaHistoryRepository
 }
 
 // NewDeckHandler creates a new deck handler
-func NewDeckHandler(battleRepo repository.BattleRepository, metaRepo repository.MetaDeckRepository) *DeckHandler {
+func NewDeckHandler(
+	battleRepo repository.BattleRepository,
+	metaRepo repository.MetaDeckRepository,
+	historyRepo repository.MetaHistoryRepository,
+) *DeckHandler {
 	return &DeckHandler{
-		battleRepo: battleRepo,
-		metaRepo:   metaRepo,
+		battleRepo:  battleRepo,
+		metaRepo:    metaRepo,
+		historyRepo: historyRepo,
 	}
 }
 
 type metaDecksResponse struct {
-	Decks    []*models.Deck `json:"decks"`
-	Metadata metadata       `json:"metadata"`
+	Decks      []*models.Deck `json:"decks"`
+	Metadata   metadata       `json:"metadata"`
+	Pagination pagination     `json:"pagination"`
 }
 
 type deckDetailResponse struct {
@@ -35,34 +43,115 @@ type deckDetailResponse struct {
 	RecentBattles []*models.Battle `json:"recent_battles"`
 }
 
+type deckHistoryResponse struct {
+	Signature string                     `json:"signature"`
+	Interval  string                     `json:"interval"`
+	Days      int                        `json:"days"`
+	Points    []*models.DeckHistoryPoint `json:"points"`
+}
+
+type trendingDecksResponse struct {
+	Decks      []*models.TrendingDeck `json:"decks"`
+	Window     trendingWindow         `json:"window"`
+	Pagination pagination             `json:"pagination"`
+}
+
+type trendingWindow struct {
+	BaselineFrom time.Time `json:"baseline_from"`
+	RecentFrom   time.Time `json:"recent_from"`
+	Until        time.Time `json:"until"`
+	MinGames     int       `json:"min_games"`
+}
+
 type metadata struct {
-	TotalDecks  int       `json:"total_decks"`
-	LastUpdated time.Time `json:"last_updated"`
+	TotalDecks  int       `json:"total_decks"`  // decks matching the filters, all pages included
+	LastUpdated time.Time `json:"last_updated"` // last meta recalculation
 }
 
 // GetMetaDecks handles GET /decks/meta
 func (h *DeckHandler) GetMetaDecks(w http.ResponseWriter, r *http.Request) {
-	ctx := context.Background()
+	ctx := r.Context()
+
+	query := newQueryParams(r)
+	page := query.Page(50)
+	sortBy := query.Enum("sort", "win_rate", "win_rate", "frequency")
+	minGames := query.Int("min_games", 10, 0, maxMinGames)
+	patchID, byPatch := query.OptionalInt("patch", 1)
+	if err := query.Err(); err != nil {
+		respond.Invalid(w, r, err)
+		return
+	}
 
-	limit := getQueryInt(r, "limit", 50)
-	sortBy := r.URL.Query().Get("sort")
-	if sortBy == "" {
-		sortBy = "win_rate"
+	var decks []*models.Deck
+	var total int
+	var err error
+	if byPatch {
+		decks, total, err = h.metaRepo.GetTopByPatch(ctx, patchID, page, sortBy, minGames)
+	} else {
+		decks, total, err = h.metaRepo.GetTop(ctx, page, sortBy, minGames)
+	}
+	if err != nil {
+		respond.Failure(w, r, err, "failed to fetch meta decks")
+		return
 	}
-	minGames := getQueryInt(r, "min_games", 10)
 
-	decks, err := h.metaRepo.GetTop(ctx, limit, sortBy, minGames)
+	lastUpdated, err := h.metaRepo.LastUpdated(ctx)
 	if err != nil {
-		http.Error(w, `{"error": "failed to fetch meta decks"}`, http.StatusInternalServerError)
+		respond.Failure(w, r, err, "failed to fetch meta decks")
 		return
 	}
 
 	response := metaDecksResponse{
 		Decks: decks,
 		Metadata: metadata{
-			TotalDecks:  len(decks),
-			LastUpdated: time.Now(),
+			TotalDecks:  total,
+			LastUpdated: lastUpdated,
+		},
+		Pagination: newPagination(page, total),
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetTrendingDecks handles GET /decks/trending
+func (h *DeckHandler) GetTrendingDecks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	query := newQueryParams(r)
+	recentDays := query.Int("recent_days", 2, 1, maxWindowDays)
+	baselineDays := query.Int("baseline_days", 5, 1, maxWindowDays)
+	minGames := query.Int("min_games", 20, 0, maxMinGames)
+	page := query.Page(20)
+	if err := query.Err(); err != nil {
+		respond.Invalid(w, r, err)
+		return
+	}
+
+	now := time.Now()
+	recentFrom := now.AddDate(0, 0, -recentDays)
+	baselineFrom := recentFrom.AddDate(0, 0, -baselineDays)
+
+	stats, err := h.metaRepo.GetWindowStats(ctx, baselineFrom, recentFrom, minGames)
+	if err != nil {
+		respond.Failure(w, r, err, "failed to fetch trending decks")
+		return
+	}
+
+	ranked := utils.RankTrendingDecks(stats, utils.TrendOptions{
+		MinScore:      utils.DefaultMinTrendScore,
+		EmergingSince: recentFrom,
+	})
+
+	response := trendingDecksResponse{
+		Decks: paginate(ranked, page),
+		Window: trendingWindow{
+			BaselineFrom: baselineFrom,
+			RecentFrom:   recentFrom,
+			Until:        now,
+			MinGames:     minGames,
 		},
+		Pagination: newPagination(page, len(ranked)),
 	}
 
 	w.WriteHeader(http.StatusOK)
@@ -71,22 +160,22 @@ func (h *DeckHandler) GetMetaDecks(w http.ResponseWriter, r *http.Request) {
 
 // GetDeckBySignature handles GET /decks/{signature}
 func (h *DeckHandler) GetDeckBySignature(w http.ResponseWriter, r *http.Request) {
-	ctx := context.Background()
+	ctx := r.Context()
 
 	signature := r.PathValue("signature")
 	if signature == "" {
-		http.Error(w, `{"error": "signature required"}`, http.StatusBadRequest)
+		respond.Invalid(w, r, &errors.ValidationError{Field: "signature", Message: "required"})
 		return
 	}
 
 	deck, err := h.metaRepo.GetBySignature(ctx, signature)
 	if err != nil {
-		http.Error(w, `{"error": "failed to fetch deck"}`, http.StatusInternalServerError)
+		respond.Failure(w, r, err, "failed to fetch deck")
 		return
 	}
 
 	if deck == nil {
-		http.Error(w, `{"error": "deck not found"}`, http.StatusNotFound)
+		respond.Error(w, r, http.StatusNotFound, respond.CodeNotFound, "deck not found")
 		return
 	}
 
@@ -104,14 +193,37 @@ func (h *DeckHandler) GetDeckBySignature(w http.ResponseWriter, r *http.Request)
 	json.NewEncoder(w).Encode(response)
 }
 
-func getQueryInt(r *http.Request, key string, defaultValue int) int {
-	value := r.URL.Query().Get(key)
-	if value == "" {
-		return defaultValue
+// GetDeckHistory handles GET /decks/{signature}/history
+func (h *DeckHandler) GetDeckHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	signature := r.PathValue("signature")
+	if signature == "" {
+		respond.Invalid(w, r, &errors.ValidationError{Field: "signature", Message: "required"})
+		return
+	}
+
+	query := newQueryParams(r)
+	interval := query.Enum("interval", repository.IntervalDay, repository.IntervalDay, repository.IntervalWeek)
+	days := query.Int("days", 90, 1, maxHistoryDays)
+	if err := query.Err(); err != nil {
+		respond.Invalid(w, r, err)
+		return
 	}
-	intValue, err := strconv.Atoi(value)
+
+	points, err := h.historyRepo.GetDeckHistory(ctx, signature, interval, days)
 	if err != nil {
-		return defaultValue
+		respond.Failure(w, r, err, "failed to fetch deck history")
+		return
 	}
-	return intValue
+
+	response := deckHistoryResponse{
+		Signature: signature,
+		Interval:  interval,
+		Days:      days,
+		Points:    points,
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
 }
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/leopoldhub/royal-api-personal/internal/api/openapi"
+	"github.com/leopoldhub/royal-api-personal/internal/api/respond"
+	"github.com/leopoldhub/royal-api-personal/internal/models"
+)
+
+// APIVersion is the version advertised by the OpenAPI document
+const APIVersion = "1.0.0"
+
+// docsPage renders /openapi.json with Redoc
+const docsPage = `<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<title>Royal API Personnel</title>
+</head>
+<body>
+	<redoc spec-url="/openapi.json"></redoc>
+	<script src="https://cdn.redoc.ly/redoc/latest/bundles/redoc.standalone.js"></script>
+</body>
+</html>
+`
+
+// DocsHandler serves the OpenAPI document and its documentation page
+type DocsHandler struct {
+	spec *openapi.Document
+}
+
+// NewDocsHandler creates a docs handler documenting endpoints
+func NewDocsHandler(endpoints []openapi.Endpoint) *DocsHandler {
+	return &DocsHandler{
+		spec: Spec(endpoints),
+	}
+}
+
+// Spec builds the OpenAPI document of endpoints
+func Spec(endpoints []openapi.Endpoint) *openapi.Document {
+	info := openapi.Info{
+		Title:   "Royal API Personnel",
+		Version: APIVersion,
+		Description: "Clash Royale meta decks computed from the battles of the top players. " +
+			"Protected routes take the Bearer token of a client granted their scope (" + strings.Join(models.Scopes, ", ") +
+			"), admin granting every scope.",
+	}
+	spec := openapi.Build(info, endpoints, respond.ErrorEnvelope{})
+
+	// Scopes are plain strings in Go, restrict them to the known values
+	for _, name := range []string{"APIClient", "CreateClientRequest"} {
+		if schema, ok := spec.Components.Schemas[name]; ok {
+			schema.Properties["scopes"].Items.Enum = models.Scopes
+		}
+	}
+	return spec
+}
+
+// GetSpec handles GET /openapi.json
+func (h *DocsHandler) GetSpec(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(h.spec)
+}
+
+// GetDocs handles GET /docs
+func (h *DocsHandler) GetDocs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(docsPage))
+}
@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"context"
 	"database/sql"
 	"encoding/json"
 	"net/http"
@@ -15,14 +14,21 @@ type HealthHandler struct {
 	db         *sql.DB
 	battleRepo repository.BattleRepository
 	metaRepo   repository.MetaDeckRepository
+	statsRepo  repository.CollectionStatsRepository
 }
 
 // NewHealthHandler creates a new health handler
-func NewHealthHandler(db *sql.DB, battleRepo repository.BattleRepository, metaRepo repository.MetaDeckRepository) *HealthHandler {
+func NewHealthHandler(
+	db *sql.DB,
+	battleRepo repository.BattleRepository,
+	metaRepo repository.MetaDeckRepository,
+	statsRepo repository.CollectionStatsRepository,
+) *HealthHandler {
 	return &HealthHandler{
 		db:         db,
 		battleRepo: battleRepo,
 		metaRepo:   metaRepo,
+		statsRepo:  statsRepo,
 	}
 }
 
@@ -36,14 +42,14 @@ type healthResponse struct {
 
 // Handle processes health check requests
 func (h *HealthHandler) Handle(w http.ResponseWriter, r *http.Request) {
-	ctx := context.Background()
+	ctx := r.Context()
 
 	response := healthResponse{
 		Status:   "healthy",
 		Database: "disconnected",
 	}
 
-	if err := h.db.Ping(); err == nil {
+	if err := h.db.PingContext(ctx); err == nil {
 		response.Database = "connected"
 	}
 
@@ -55,6 +61,10 @@ func (h *HealthHandler) Handle(w http.ResponseWriter, r *http.Request) {
 		response.TotalDecks = totalDecks
 	}
 
+	if last, err := h.statsRepo.GetLatest(ctx); err == nil && last != nil {
+		response.LastCollection = last.CompletedAt
+	}
+
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
 }
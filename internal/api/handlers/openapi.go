@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/leopoldhub/royal-api-personal/internal/api/openapi"
+	"github.com/leopoldhub/royal-api-personal/internal/database/repository"
+	"github.com/leopoldhub/royal-api-personal/internal/models"
+)
+
+// page documents the limit and offset query parameters read by queryParams.Page
+func page(defaultLimit int) []openapi.Parameter {
+	return []openapi.Parameter{
+		openapi.QueryInt("limit", "Page size", defaultLimit, 1, maxPageLimit),
+		openapi.QueryInt("offset", "Number of items to skip", 0, 0, maxOffset),
+	}
+}
+
+// Endpoints documents every route registered by the server, in registration order
+var Endpoints = []openapi.Endpoint{
+	{
+		Method:   http.MethodGet,
+		Path:     "/health",
+		Summary:  "Service and database health",
+		Status:   http.StatusOK,
+		Response: healthResponse{},
+	},
+	{
+		Method:  http.MethodGet,
+		Path:    "/openapi.json",
+		Summary: "This OpenAPI document",
+		Status:  http.StatusOK,
+	},
+	{
+		Method:  http.MethodGet,
+		Path:    "/docs",
+		Summary: "API documentation page rendering this document",
+		Status:  http.StatusOK,
+	},
+	{
+		Method:  http.MethodGet,
+		Path:    "/decks/meta",
+		Summary: "Meta decks sorted by win rate or frequency",
+		Scope:   models.ScopeReadDecks,
+		Params: append(page(50),
+			openapi.QueryEnum("sort", "Sort order", "win_rate", "win_rate", "frequency"),
+			openapi.QueryInt("min_games", "Minimum games played", 10, 0, maxMinGames),
+			openapi.QueryID("patch", "Restrict statistics to the battles of a patch"),
+		),
+		Status:   http.StatusOK,
+		Response: metaDecksResponse{},
+		Errors:   []int{http.StatusBadRequest, http.StatusInternalServerError},
+		Cached:   true,
+	},
+	{
+		Method:  http.MethodGet,
+		Path:    "/decks/trending",
+		Summary: "Decks whose usage share rises significantly",
+		Scope:   models.ScopeReadDecks,
+		Params: append([]openapi.Parameter{
+			openapi.QueryInt("recent_days", "Recent window in days", 2, 1, maxWindowDays),
+			openapi.QueryInt("baseline_days", "Baseline window in days, right before the recent one", 5, 1, maxWindowDays),
+			openapi.QueryInt("min_games", "Minimum games in the recent window", 20, 0, maxMinGames),
+		}, page(20)...),
+		Status:   http.StatusOK,
+		Response: trendingDecksResponse{},
+		Errors:   []int{http.StatusBadRequest, http.StatusInternalServerError},
+		Cached:   true,
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/decks/{signature}",
+		Summary:  "Deck statistics and recent battles",
+		Scope:    models.ScopeReadDecks,
+		Params:   []openapi.Parameter{openapi.PathString("signature", "Deck signature")},
+		Status:   http.StatusOK,
+		Response: deckDetailResponse{},
+		Errors:   []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError},
+		Cached:   true,
+	},
+	{
+		Method:  http.MethodGet,
+		Path:    "/decks/{signature}/history",
+		Summary: "Daily or weekly usage and win rate of a deck",
+		Scope:   models.ScopeReadDecks,
+		Params: []openapi.Parameter{
+			openapi.PathString("signature", "Deck signature"),
+			openapi.QueryEnum("interval", "Bucket size", repository.IntervalDay, repository.IntervalDay, repository.IntervalWeek),
+			openapi.QueryInt("days", "Days of history", 90, 1, maxHistoryDays),
+		},
+		Status:   http.StatusOK,
+		Response: deckHistoryResponse{},
+		Errors:   []int{http.StatusBadRequest, http.StatusInternalServerError},
+		Cached:   true,
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/patches",
+		Summary:  "Balance patches, most recent first",
+		Scope:    models.ScopeReadDecks,
+		Params:   page(50),
+		Status:   http.StatusOK,
+		Response: patchesResponse{},
+		Errors:   []int{http.StatusBadRequest, http.StatusInternalServerError},
+		Cached:   true,
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     "/patches",
+		Summary:  "Declare a balance patch and tag its battles",
+		Scope:    models.ScopeAdmin,
+		Body:     createPatchRequest{},
+		Status:   http.StatusCreated,
+		Response: models.Patch{},
+		Errors:   []int{http.StatusBadRequest, http.StatusInternalServerError},
+	},
+	{
+		Method:  http.MethodDelete,
+		Path:    "/patches/{id}",
+		Summary: "Delete a patch, its battles move back to the previous patch",
+		Scope:   models.ScopeAdmin,
+		Params:  []openapi.Parameter{openapi.PathInt("id", "Patch ID")},
+		Status:  http.StatusNoContent,
+		Errors:  []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError},
+	},
+	{
+		Method:  http.MethodGet,
+		Path:    "/patches/{id}/compare",
+		Summary: "Card and deck win rates before and after a patch",
+		Scope:   models.ScopeReadDecks,
+		Params: []openapi.Parameter{
+			openapi.PathInt("id", "Patch ID"),
+			openapi.QueryInt("min_games", "Minimum games on each side", 20, 0, maxMinGames),
+		},
+		Status:   http.StatusOK,
+		Response: models.PatchComparison{},
+		Errors:   []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError},
+		Cached:   true,
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/stats/summary",
+		Summary:  "Global statistics",
+		Scope:    models.ScopeReadStats,
+		Status:   http.StatusOK,
+		Response: summaryResponse{},
+		Cached:   true,
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/stats/collections",
+		Summary:  "Completed collection runs, most recent first",
+		Scope:    models.ScopeReadStats,
+		Params:   page(20),
+		Status:   http.StatusOK,
+		Response: collectionsResponse{},
+		Errors:   []int{http.StatusBadRequest, http.StatusInternalServerError},
+		Cached:   true,
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/stats/rejections",
+		Summary:  "Battles rejected by the parser, by reason",
+		Scope:    models.ScopeReadStats,
+		Params:   []openapi.Parameter{openapi.QueryInt("days", "Days to look back", 7, 1, maxHistoryDays)},
+		Status:   http.StatusOK,
+		Response: rejectionsResponse{},
+		Errors:   []int{http.StatusBadRequest, http.StatusInternalServerError},
+		Cached:   true,
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/clients",
+		Summary:  "API clients, revoked ones included",
+		Scope:    models.ScopeAdmin,
+		Status:   http.StatusOK,
+		Response: clientsResponse{},
+		Errors:   []int{http.StatusInternalServerError},
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     "/clients",
+		Summary:  "Create an API client, its token is only returned in this response",
+		Scope:    models.ScopeAdmin,
+		Body:     createClientRequest{},
+		Status:   http.StatusCreated,
+		Response: createClientResponse{},
+		Errors:   []int{http.StatusBadRequest, http.StatusInternalServerError},
+	},
+	{
+		Method:  http.MethodDelete,
+		Path:    "/clients/{id}",
+		Summary: "Revoke the token of an API client",
+		Scope:   models.ScopeAdmin,
+		Params:  []openapi.Parameter{openapi.PathInt("id", "Client ID")},
+		Status:  http.StatusNoContent,
+		Errors:  []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError},
+	},
+	{
+		Method:  http.MethodGet,
+		Path:    "/metrics",
+		Summary: "Prometheus metrics in the text exposition format",
+		Scope:   models.ScopeReadMetrics,
+		Status:  http.StatusOK,
+		Errors:  []int{http.StatusInternalServerError},
+	},
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import "github.com/leopoldhub/royal-api-personal/internal/models"
+
+// maxPageLimit and maxOffset bound the limit and offset query parameters of list endpoints
+const (
+	maxPageLimit = 500
+	maxOffset    = 1_000_000
+)
+
+type pagination struct {
+	Limit      int  `json:"limit"`
+	Offset     int  `json:"offset"`
+	Total      int  `json:"total"`       // items matching the filters, all pages included
+	NextOffset *int `json:"next_offset"` // nil on the last page
+}
+
+func newPagination(page models.Page, total int) pagination {
+	p := pagination{
+		Limit:  page.Limit,
+		Offset: page.Offset,
+		Total:  total,
+	}
+	if next := page.Offset + page.Limit; next < total {
+		p.NextOffset = &next
+	}
+	return p
+}
+
+// paginate returns the items of page from an in-memory list
+func paginate[T any](items []T, page models.Page) []T {
+	if page.Offset >= len(items) {
+		return []T{}
+	}
+	end := page.Offset + page.Limit
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[page.Offset:end]
+}
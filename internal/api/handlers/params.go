@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/leopoldhub/royal-api-personal/internal/errors"
+	"github.com/leopoldhub/royal-api-personal/internal/models"
+)
+
+// Upper bounds of the query parameters shared by several endpoints
+const (
+	maxMinGames    = 1_000_000
+	maxWindowDays  = 90
+	maxHistoryDays = 730
+)
+
+// queryParams reads and validates query parameters, keeping the first invalid one
+type queryParams struct {
+	values url.Values
+	err    *errors.ValidationError
+}
+
+func newQueryParams(r *http.Request) *queryParams {
+	return &queryParams{values: r.URL.Query()}
+}
+
+// Err returns the first validation error, nil when every parameter is valid
+func (q *queryParams) Err() *errors.ValidationError {
+	return q.err
+}
+
+func (q *queryParams) fail(field, format string, args ...any) {
+	if q.err == nil {
+		q.err = &errors.ValidationError{Field: field, Message: fmt.Sprintf(format, args...)}
+	}
+}
+
+// Int returns the integer parameter key within [min, max], or defaultValue when absent
+func (q *queryParams) Int(key string, defaultValue, min, max int) int {
+	value := q.values.Get(key)
+	if value == "" {
+		return defaultValue
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		q.fail(key, "must be an integer")
+		return defaultValue
+	}
+	if n < min || n > max {
+		q.fail(key, "must be between %d and %d", min, max)
+		return defaultValue
+	}
+	return n
+}
+
+// OptionalInt returns the integer parameter key, at least min, and whether it is set
+func (q *queryParams) OptionalInt(key string, min int) (int, bool) {
+	value := q.values.Get(key)
+	if value == "" {
+		return 0, false
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < min {
+		q.fail(key, "must be an integer of at least %d", min)
+		return 0, false
+	}
+	return n, true
+}
+
+// Enum returns the parameter key when it is one of allowed, or defaultValue when absent
+func (q *queryParams) Enum(key, defaultValue string, allowed ...string) string {
+	value := q.values.Get(key)
+	if value == "" {
+		return defaultValue
+	}
+	for _, candidate := range allowed {
+		if value == candidate {
+			return value
+		}
+	}
+	q.fail(key, "must be one of %s", strings.Join(allowed, ", "))
+	return defaultValue
+}
+
+// Page returns the limit and offset parameters, limit being at most maxPageLimit
+func (q *queryParams) Page(defaultLimit int) models.Page {
+	return models.Page{
+		Limit:  q.Int("limit", defaultLimit, 1, maxPageLimit),
+		Offset: q.Int("offset", 0, 0, maxOffset),
+	}
+}
+
+// pathInt returns the positive integer path parameter key
+func pathInt(r *http.Request, key string) (int, *errors.ValidationError) {
+	n, err := strconv.Atoi(r.PathValue(key))
+	if err != nil || n < 1 {
+		return 0, &errors.ValidationError{Field: key, Message: "must be a positive integer"}
+	}
+	return n, nil
+}
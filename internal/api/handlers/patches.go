@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"time"
+
+	"github.com/leopoldhub/royal-api-personal/internal/api/respond"
+	"github.com/leopoldhub/royal-api-personal/internal/database/repository"
+	"github.com/leopoldhub/royal-api-personal/internal/errors"
+	"github.com/leopoldhub/royal-api-personal/internal/models"
+)
+
+// PatchHandler handles balance patch requests
+type PatchHandler struct {
+	patchRepo repository.PatchRepository
+}
+
+// NewPatchHandler creates a new patch handler
+func NewPatchHandler(patchRepo repository.PatchRepository) *PatchHandler {
+	return &PatchHandler{
+		patchRepo: patchRepo,
+	}
+}
+
+type patchesResponse struct {
+	Patches    []*models.Patch `json:"patches"`
+	Pagination pagination      `json:"pagination"`
+}
+
+type createPatchRequest struct {
+	ReleasedAt    time.Time `json:"released_at"`
+	Description   string    `json:"description"`
+	AffectedCards []string  `json:"affected_cards"`
+}
+
+// ListPatches handles GET /patches
+func (h *PatchHandler) ListPatches(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	query := newQueryParams(r)
+	page := query.Page(50)
+	if err := query.Err(); err != nil {
+		respond.Invalid(w, r, err)
+		return
+	}
+
+	patches, total, err := h.patchRepo.List(ctx, page)
+	if err != nil {
+		respond.Failure(w, r, err, "failed to fetch patches")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(patchesResponse{
+		Patches:    patches,
+		Pagination: newPagination(page, total),
+	})
+}
+
+// CreatePatch handles POST /patches
+func (h *PatchHandler) CreatePatch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req createPatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respond.Error(w, r, http.StatusBadRequest, respond.CodeInvalidBody, "invalid JSON body")
+		return
+	}
+	if req.ReleasedAt.IsZero() {
+		respond.Invalid(w, r, &errors.ValidationError{Field: "released_at", Message: "required"})
+		return
+	}
+
+	// released_at is stored without time zone, battle times being UTC
+	patch := &models.Patch{
+		ReleasedAt:    req.ReleasedAt.UTC(),
+		Description:   req.Description,
+		AffectedCards: req.AffectedCards,
+	}
+	if patch.AffectedCards == nil {
+		patch.AffectedCards = []string{}
+	}
+
+	if err := h.patchRepo.Create(ctx, patch); err != nil {
+		var invalid *errors.ValidationError
+		if stderrors.As(err, &invalid) {
+			respond.Invalid(w, r, invalid)
+			return
+		}
+		respond.Failure(w, r, err, "failed to create patch")
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(patch)
+}
+
+// DeletePatch handles DELETE /patches/{id}
+func (h *PatchHandler) DeletePatch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, invalid := pathInt(r, "id")
+	if invalid != nil {
+		respond.Invalid(w, r, invalid)
+		return
+	}
+
+	deleted, err := h.patchRepo.Delete(ctx, id)
+	if err != nil {
+		respond.Failure(w, r, err, "failed to delete patch")
+		return
+	}
+	if !deleted {
+		respond.Error(w, r, http.StatusNotFound, respond.CodeNotFound, "patch not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ComparePatch handles GET /patches/{id}/compare
+func (h *PatchHandler) ComparePatch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, invalid := pathInt(r, "id")
+	if invalid != nil {
+		respond.Invalid(w, r, invalid)
+		return
+	}
+	query := newQueryParams(r)
+	minGames := query.Int("min_games", 20, 0, maxMinGames)
+	if err := query.Err(); err != nil {
+		respond.Invalid(w, r, err)
+		return
+	}
+
+	patch, err := h.patchRepo.GetByID(ctx, id)
+	if err != nil {
+		respond.Failure(w, r, err, "failed to fetch patch")
+		return
+	}
+	if patch == nil {
+		respond.Error(w, r, http.StatusNotFound, respond.CodeNotFound, "patch not found")
+		return
+	}
+
+	comparison, err := h.patchRepo.Compare(ctx, patch, minGames)
+	if err != nil {
+		respond.Failure(w, r, err, "failed to compare patch")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(comparison)
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leopoldhub/royal-api-personal/internal/api/respond"
+	"github.com/leopoldhub/royal-api-personal/internal/errors"
+	"github.com/leopoldhub/royal-api-personal/internal/models"
+)
+
+// fakePatchRepo records the created patch and fails Create with createErr
+type fakePatchRepo struct {
+	created   *models.Patch
+	createErr error
+}
+
+func (f *fakePatchRepo) Create(ctx context.Context, patch *models.Patch) error {
+	if f.createErr != nil {
+		return f.createErr
+	}
+	f.created = patch
+	patch.ID = 1
+	return nil
+}
+
+func (f *fakePatchRepo) List(ctx context.Context, page models.Page) ([]*models.Patch, int, error) {
+	return nil, 0, nil
+}
+
+func (f *fakePatchRepo) GetByID(ctx context.Context, id int) (*models.Patch, error) {
+	return nil, nil
+}
+
+func (f *fakePatchRepo) Delete(ctx context.Context, id int) (bool, error) {
+	return false, nil
+}
+
+func (f *fakePatchRepo) Compare(ctx context.Context, patch *models.Patch, minGames int) (*models.PatchComparison, error) {
+	return nil, nil
+}
+
+func postPatch(h *PatchHandler, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/patches", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.CreatePatch(rec, req)
+	return rec
+}
+
+func TestCreatePatchNormalizesReleaseToUTC(t *testing.T) {
+	repo := &fakePatchRepo{}
+	h := NewPatchHandler(repo)
+
+	rec := postPatch(h, `{"released_at": "2026-02-02T10:00:00+02:00"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+
+	want := time.Date(2026, 2, 2, 8, 0, 0, 0, time.UTC)
+	got := repo.created.ReleasedAt
+	if got.Location() != time.UTC || !got.Equal(want) {
+		t.Errorf("released_at = %v, want %v", got, want)
+	}
+	if repo.created.AffectedCards == nil {
+		t.Error("affected_cards is nil, want an empty list")
+	}
+}
+
+func TestCreatePatchValidationError(t *testing.T) {
+	repo := &fakePatchRepo{
+		createErr: &errors.ValidationError{Field: "released_at", Message: "already used by another patch"},
+	}
+	h := NewPatchHandler(repo)
+
+	rec := postPatch(h, `{"released_at": "2026-02-02T08:00:00Z"}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	var envelope respond.ErrorEnvelope
+	if err := json.NewDecoder(rec.Body).Decode(&envelope); err != nil {
+		t.Fatalf("failed to decode error: %v", err)
+	}
+	if envelope.Error.Code != respond.CodeInvalidParameter || envelope.Error.Field != "released_at" {
+		t.Errorf("error = %+v, want %s on released_at", envelope.Error, respond.CodeInvalidParameter)
+	}
+}
@@ -1,25 +1,36 @@
 package handlers
 
 import (
-	"context"
 	"encoding/json"
+	"math"
 	"net/http"
 	"time"
 
+	"github.com/leopoldhub/royal-api-personal/internal/api/respond"
 	"github.com/leopoldhub/royal-api-personal/internal/database/repository"
+	"github.com/leopoldhub/royal-api-personal/internal/models"
 )
 
 // StatsHandler handles statistics requests
 type StatsHandler struct {
-	battleRepo repository.BattleRepository
-	metaRepo   repository.MetaDeckRepository
+	battleRepo   repository.BattleRepository
+	metaRepo     repository.MetaDeckRepository
+	statsRepo    repository.CollectionStatsRepository
+	rejectedRepo repository.RejectedBattleRepository
 }
 
 // NewStatsHandler creates a new stats handler
-func NewStatsHandler(battleRepo repository.BattleRepository, metaRepo repository.MetaDeckRepository) *StatsHandler {
+func NewStatsHandler(
+	battleRepo repository.BattleRepository,
+	metaRepo repository.MetaDeckRepository,
+	statsRepo repository.CollectionStatsRepository,
+	rejectedRepo repository.RejectedBattleRepository,
+) *StatsHandler {
 	return &StatsHandler{
-		battleRepo: battleRepo,
-		metaRepo:   metaRepo,
+		battleRepo:   battleRepo,
+		metaRepo:     metaRepo,
+		statsRepo:    statsRepo,
+		rejectedRepo: rejectedRepo,
 	}
 }
 
@@ -31,10 +42,27 @@ type summaryResponse struct {
 }
 
 type collectionStats struct {
-	TotalBattles   int       `json:"total_battles"`
-	TotalDecks     int       `json:"total_decks"`
-	LastCollection time.Time `json:"last_collection"`
-	PlayersTracked int       `json:"players_tracked"`
+	TotalBattles    int       `json:"total_battles"`
+	TotalDecks      int       `json:"total_decks"`
+	LastCollection  time.Time `json:"last_collection"`
+	PlayersTracked  int       `json:"players_tracked"`
+	CoveragePercent float64   `json:"coverage_percent"`
+}
+
+type collectionsResponse struct {
+	Collections []collectionRun `json:"collections"`
+	Pagination  pagination      `json:"pagination"`
+}
+
+type collectionRun struct {
+	*models.CollectionStats
+	CoveragePercent float64 `json:"coverage_percent"`
+}
+
+type rejectionsResponse struct {
+	Days    int            `json:"days"`
+	Total   int            `json:"total"`
+	Reasons map[string]int `json:"reasons"`
 }
 
 type deckSummary struct {
@@ -51,15 +79,19 @@ type cardUsage struct {
 
 // GetSummary handles GET /stats/summary
 func (h *StatsHandler) GetSummary(w http.ResponseWriter, r *http.Request) {
-	ctx := context.Background()
+	ctx := r.Context()
 
 	response := summaryResponse{
-		Collection: collectionStats{
-			PlayersTracked: 1000,
-		},
 		TopCards: []cardUsage{},
 	}
 
+	// Players and coverage are those of the last completed run, zero before the first one
+	if last, err := h.statsRepo.GetLatest(ctx); err == nil && last != nil {
+		response.Collection.LastCollection = last.CompletedAt
+		response.Collection.PlayersTracked = last.PlayersProcessed
+		response.Collection.CoveragePercent = roundPercent(last.Coverage())
+	}
+
 	if totalBattles, err := h.battleRepo.Count(ctx); err == nil {
 		response.Collection.TotalBattles = totalBattles
 	}
@@ -68,7 +100,7 @@ func (h *StatsHandler) GetSummary(w http.ResponseWriter, r *http.Request) {
 		response.Collection.TotalDecks = totalDecks
 	}
 
-	topByFrequency, err := h.metaRepo.GetTop(ctx, 1, "frequency", 1)
+	topByFrequency, _, err := h.metaRepo.GetTop(ctx, models.Page{Limit: 1}, "frequency", 1)
 	if err == nil && len(topByFrequency) > 0 {
 		deck := topByFrequency[0]
 		response.TopDeck = &deckSummary{
@@ -77,7 +109,7 @@ func (h *StatsHandler) GetSummary(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	topByWinRate, err := h.metaRepo.GetTop(ctx, 1, "win_rate", 20)
+	topByWinRate, _, err := h.metaRepo.GetTop(ctx, models.Page{Limit: 1}, "win_rate", 20)
 	if err == nil && len(topByWinRate) > 0 {
 		deck := topByWinRate[0]
 		response.BestDeck = &deckSummary{
@@ -89,3 +121,68 @@ func (h *StatsHandler) GetSummary(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
 }
+
+// GetCollections handles GET /stats/collections
+func (h *StatsHandler) GetCollections(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	query := newQueryParams(r)
+	page := query.Page(20)
+	if err := query.Err(); err != nil {
+		respond.Invalid(w, r, err)
+		return
+	}
+
+	runs, total, err := h.statsRepo.GetRecent(ctx, page)
+	if err != nil {
+		respond.Failure(w, r, err, "failed to fetch collections")
+		return
+	}
+
+	response := collectionsResponse{
+		Collections: make([]collectionRun, 0, len(runs)),
+	}
+	for _, run := range runs {
+		response.Collections = append(response.Collections, collectionRun{
+			CollectionStats: run,
+			CoveragePercent: roundPercent(run.Coverage()),
+		})
+	}
+	response.Pagination = newPagination(page, total)
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetRejections handles GET /stats/rejections
+func (h *StatsHandler) GetRejections(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	query := newQueryParams(r)
+	days := query.Int("days", 7, 1, maxHistoryDays)
+	if err := query.Err(); err != nil {
+		respond.Invalid(w, r, err)
+		return
+	}
+
+	reasons, err := h.rejectedRepo.CountByReason(ctx, days)
+	if err != nil {
+		respond.Failure(w, r, err, "failed to fetch rejections")
+		return
+	}
+
+	response := rejectionsResponse{
+		Days:    days,
+		Reasons: reasons,
+	}
+	for _, count := range reasons {
+		response.Total += count
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func roundPercent(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -1,27 +1,51 @@
 package middleware
 
 import (
+	"context"
+	stderrors "errors"
 	"net/http"
 	"strings"
+
+	"github.com/leopoldhub/royal-api-personal/internal/api/respond"
+	"github.com/leopoldhub/royal-api-personal/internal/errors"
+	"github.com/leopoldhub/royal-api-personal/internal/models"
 )
 
-// Auth validates Bearer token authentication
-func Auth(apiToken string) func(http.Handler) http.Handler {
+// Authenticator resolves a Bearer token into the API client owning it
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*models.APIClient, error)
+}
+
+// Auth validates Bearer token authentication and requires scope, attaching the
+// client to the request context
+func Auth(authenticator Authenticator, scope string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			auth := r.Header.Get("Authorization")
 			if !strings.HasPrefix(auth, "Bearer ") {
-				http.Error(w, `{"error": "unauthorized", "message": "missing Bearer token"}`, http.StatusUnauthorized)
+				respond.Error(w, r, http.StatusUnauthorized, respond.CodeUnauthorized, "missing Bearer token")
 				return
 			}
 
 			token := strings.TrimPrefix(auth, "Bearer ")
-			if token != apiToken {
-				http.Error(w, `{"error": "unauthorized", "message": "invalid token"}`, http.StatusUnauthorized)
+			client, err := authenticator.Authenticate(r.Context(), token)
+			if err != nil {
+				var authErr *errors.AuthError
+				if stderrors.As(err, &authErr) {
+					respond.Error(w, r, http.StatusUnauthorized, respond.CodeUnauthorized, authErr.Message)
+					return
+				}
+				respond.Failure(w, r, err, "failed to verify token")
+				return
+			}
+
+			setLogClient(r.Context(), client.Name)
+			if !client.HasScope(scope) {
+				respond.Error(w, r, http.StatusForbidden, respond.CodeForbidden, "missing scope "+scope)
 				return
 			}
 
-			next.ServeHTTP(w, r)
+			next.ServeHTTP(w, r.WithContext(respond.WithClient(r.Context(), client)))
 		})
 	}
 }
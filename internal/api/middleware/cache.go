@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/leopoldhub/royal-api-personal/internal/api/cache"
+	"github.com/leopoldhub/royal-api-personal/internal/models"
+)
+
+// VersionSource returns the version of the served data
+type VersionSource interface {
+	Get(ctx context.Context) (*models.DataVersion, error)
+}
+
+// captureWriter buffers the response of a cached handler
+type captureWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (cw *captureWriter) WriteHeader(status int) {
+	cw.status = status
+}
+
+func (cw *captureWriter) Write(b []byte) (int, error) {
+	return cw.body.Write(b)
+}
+
+// Cache serves successful GET responses from store while the data version is
+// unchanged, with ETag and Last-Modified validators answering conditional
+// requests with 304, and Cache-Control allowing the client to reuse responses
+// for maxAge. Cached routes require a Bearer token, so shared caches must not
+// store them. Responses are shared between clients in store, so only routes
+// whose response does not depend on the client may be cached.
+func Cache(store *cache.Store, versions VersionSource, maxAge time.Duration) func(http.Handler) http.Handler {
+	cacheControl := "private, max-age=" + strconv.Itoa(int(maxAge.Seconds())) + ", must-revalidate"
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			version, err := versions.Get(r.Context())
+			if err != nil {
+				// Serve uncached rather than failing on the version lookup
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := r.URL.Path + "?" + r.URL.Query().Encode()
+			lastModified := version.UpdatedAt.UTC().Truncate(time.Second)
+
+			entry, ok := store.Get(key, version.Version)
+			if !ok {
+				cw := &captureWriter{ResponseWriter: w, status: http.StatusOK}
+				next.ServeHTTP(cw, r)
+
+				if cw.status != http.StatusOK {
+					w.WriteHeader(cw.status)
+					w.Write(cw.body.Bytes())
+					return
+				}
+				entry = cache.NewEntry(version.Version, cw.body.Bytes(), w.Header().Get("Content-Type"))
+				store.Set(key, entry)
+			}
+
+			w.Header().Set("ETag", entry.ETag)
+			w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+			w.Header().Set("Cache-Control", cacheControl)
+			w.Header().Set("Vary", "Authorization")
+
+			if notModified(r, entry.ETag, lastModified) {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+
+			if entry.ContentType != "" {
+				w.Header().Set("Content-Type", entry.ContentType)
+			}
+			w.WriteHeader(http.StatusOK)
+			w.Write(entry.Body)
+		})
+	}
+}
+
+// notModified evaluates If-None-Match, or If-Modified-Since when absent (RFC 9110)
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		return etagMatches(match, etag)
+	}
+	if since := r.Header.Get("If-Modified-Since"); since != "" {
+		t, err := http.ParseTime(since)
+		return err == nil && !lastModified.After(t)
+	}
+	return false
+}
+
+// etagMatches reports whether the If-None-Match list contains etag, weak comparison
+func etagMatches(header, etag string) bool {
+	for _, candidate := range bytes.Split([]byte(header), []byte(",")) {
+		candidate = bytes.TrimSpace(candidate)
+		candidate = bytes.TrimPrefix(candidate, []byte("W/"))
+		if string(candidate) == "*" || string(candidate) == etag {
+			return true
+		}
+	}
+	return false
+}
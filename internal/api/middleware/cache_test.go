@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/leopoldhub/royal-api-personal/internal/api/cache"
+	"github.com/leopoldhub/royal-api-personal/internal/models"
+)
+
+type fakeVersions struct {
+	version *models.DataVersion
+	err     error
+}
+
+func (f *fakeVersions) Get(ctx context.Context) (*models.DataVersion, error) {
+	return f.version, f.err
+}
+
+func newCachedHandler(versions VersionSource, calls *int) http.Handler {
+	return Cache(cache.NewStore(10), versions, time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("fail") != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{}}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"decks":[]}`))
+	}))
+}
+
+func TestCache_ServesUntilVersionChanges(t *testing.T) {
+	versions := &fakeVersions{version: &models.DataVersion{Version: 1, UpdatedAt: time.Now()}}
+	calls := 0
+	handler := newCachedHandler(versions, &calls)
+
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/decks/meta?limit=10", nil))
+		if rec.Code != http.StatusOK || rec.Body.String() != `{"decks":[]}` {
+			t.Fatalf("response = %d %s", rec.Code, rec.Body)
+		}
+		if rec.Header().Get("ETag") == "" || rec.Header().Get("Last-Modified") == "" {
+			t.Error("validators missing")
+		}
+		if rec.Header().Get("Cache-Control") != "private, max-age=60, must-revalidate" {
+			t.Errorf("Cache-Control = %q", rec.Header().Get("Cache-Control"))
+		}
+	}
+	if calls != 1 {
+		t.Errorf("handler called %d times, want 1", calls)
+	}
+
+	versions.version = &models.DataVersion{Version: 2, UpdatedAt: time.Now()}
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/decks/meta?limit=10", nil))
+	if calls != 2 {
+		t.Errorf("handler called %d times after version change, want 2", calls)
+	}
+}
+
+func TestCache_ConditionalRequests(t *testing.T) {
+	updated := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	versions := &fakeVersions{version: &models.DataVersion{Version: 1, UpdatedAt: updated}}
+	calls := 0
+	handler := newCachedHandler(versions, &calls)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/decks/meta", nil))
+	etag := rec.Header().Get("ETag")
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		want   int
+	}{
+		{"matching etag", "If-None-Match", etag, http.StatusNotModified},
+		{"etag in list", "If-None-Match", `"other", W/` + etag, http.StatusNotModified},
+		{"stale etag", "If-None-Match", `"other"`, http.StatusOK},
+		{"not modified since", "If-Modified-Since", updated.Format(http.TimeFormat), http.StatusNotModified},
+		{"modified since", "If-Modified-Since", updated.Add(-time.Hour).Format(http.TimeFormat), http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/decks/meta", nil)
+			req.Header.Set(tt.header, tt.value)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if rec.Code == http.StatusNotModified && rec.Body.Len() != 0 {
+				t.Error("304 response has a body")
+			}
+		})
+	}
+}
+
+func TestCache_SkipsErrors(t *testing.T) {
+	calls := 0
+	handler := newCachedHandler(&fakeVersions{version: &models.DataVersion{Version: 1}}, &calls)
+
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/decks/meta?fail=1", nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+		}
+		if rec.Header().Get("ETag") != "" {
+			t.Error("error response has an ETag")
+		}
+	}
+	if calls != 2 {
+		t.Errorf("handler called %d times, want 2", calls)
+	}
+
+	handler = newCachedHandler(&fakeVersions{err: errors.New("connection refused")}, &calls)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/decks/meta", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") != "" {
+		t.Errorf("version failure: status = %d, ETag = %q, want uncached 200", rec.Code, rec.Header().Get("ETag"))
+	}
+}
@@ -1,9 +1,12 @@
 package middleware
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"time"
+
+	"github.com/leopoldhub/royal-api-personal/internal/api/respond"
 )
 
 type responseWriter struct {
@@ -23,6 +26,20 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 	return size, err
 }
 
+// logEntry collects the request fields only known to inner handlers
+type logEntry struct {
+	client string
+}
+
+type logEntryKey struct{}
+
+// setLogClient records the authenticated client in the log line of the request
+func setLogClient(ctx context.Context, client string) {
+	if entry, ok := ctx.Value(logEntryKey{}).(*logEntry); ok {
+		entry.client = client
+	}
+}
+
 // Logging logs HTTP requests
 func Logging(logger *log.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -34,10 +51,13 @@ func Logging(logger *log.Logger) func(http.Handler) http.Handler {
 				status:         http.StatusOK,
 			}
 
-			next.ServeHTTP(rw, r)
+			entry := &logEntry{client: "-"}
+			next.ServeHTTP(rw, r.WithContext(context.WithValue(r.Context(), logEntryKey{}, entry)))
 
 			duration := time.Since(start)
-			logger.Printf("%s %s %d %d bytes %v",
+			logger.Printf("%s %s %s %s %d %d bytes %v",
+				respond.RequestID(r.Context()),
+				entry.client,
 				r.Method,
 				r.URL.Path,
 				rw.status,
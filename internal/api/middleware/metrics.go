@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/leopoldhub/royal-api-personal/internal/metrics"
+)
+
+// HTTPMetrics holds the request metrics recorded by Metrics
+type HTTPMetrics struct {
+	requests *metrics.Counter
+	duration *metrics.Histogram
+}
+
+// NewHTTPMetrics registers the request metrics in registry
+func NewHTTPMetrics(registry *metrics.Registry) *HTTPMetrics {
+	return &HTTPMetrics{
+		requests: registry.Counter("royal_http_requests_total",
+			"HTTP requests by route pattern and status code", "route", "status"),
+		duration: registry.Histogram("royal_http_request_duration_seconds",
+			"HTTP request latency by route pattern", metrics.DefaultBuckets, "route"),
+	}
+}
+
+// Metrics counts the requests of route by status and records their latency.
+// route is the registered pattern, not the request path, to bound cardinality.
+func Metrics(m *HTTPMetrics, route string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			rw := &responseWriter{
+				ResponseWriter: w,
+				status:         http.StatusOK,
+			}
+			next.ServeHTTP(rw, r)
+
+			m.requests.Inc(route, strconv.Itoa(rw.status))
+			m.duration.Observe(time.Since(start).Seconds(), route)
+		})
+	}
+}
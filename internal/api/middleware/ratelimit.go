@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/leopoldhub/royal-api-personal/internal/api/ratelimit"
+	"github.com/leopoldhub/royal-api-personal/internal/api/respond"
+)
+
+// RateLimit limits the requests of each API client, or of each IP address on
+// public routes, to rate. The key is prefixed with scope so each scope has its
+// own quota. Responses carry the RateLimit-Limit, RateLimit-Remaining and
+// RateLimit-Reset headers, refused requests get 429 with Retry-After.
+func RateLimit(limiter ratelimit.Limiter, scope string, rate ratelimit.Rate) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			decision, err := limiter.Allow(r.Context(), scope+"|"+rateLimitKey(r), rate)
+			if err != nil {
+				// Do not refuse traffic because the limiter store is unavailable
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			reset := strconv.Itoa(int(math.Ceil(decision.Reset.Seconds())))
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(decision.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+			w.Header().Set("RateLimit-Reset", reset)
+
+			if !decision.Allowed {
+				w.Header().Set("Retry-After", reset)
+				respond.Error(w, r, http.StatusTooManyRequests, respond.CodeRateLimited, "rate limit exceeded, retry in "+reset+"s")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rateLimitKey identifies the requester, the client name once authenticated
+func rateLimitKey(r *http.Request) string {
+	if client := respond.Client(r.Context()); client != nil {
+		return "client:" + client.Name
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
@@ -0,0 +1,237 @@
+package openapi
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Version of the OpenAPI specification produced by Build
+const Version = "3.0.3"
+
+const bearerScheme = "bearerAuth"
+
+// Document is an OpenAPI 3 document
+type Document struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       Info                             `json:"info"`
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components Components                       `json:"components"`
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Components holds the schemas referenced by operations
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+// SecurityScheme describes an authentication method
+type SecurityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme"`
+}
+
+// Operation is a method on a path
+type Operation struct {
+	Summary     string                `json:"summary"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+}
+
+// Parameter is a path or query parameter
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody is the JSON body of an operation
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response is the response of an operation for a status code
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a body
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Endpoint documents a route registered by the server
+type Endpoint struct {
+	Method   string
+	Path     string // ServeMux pattern path, {name} wildcards match OpenAPI path templates
+	Summary  string
+	Scope    string // scope required from the Bearer token, empty for public routes
+	Params   []Parameter
+	Body     any // request body value, nil when none
+	Status   int // success status
+	Response any // success body value, nil when none
+	Errors   []int
+	Cached   bool // supports conditional requests with ETag and Last-Modified
+}
+
+// Pattern returns the ServeMux pattern of the endpoint
+func (e Endpoint) Pattern() string {
+	return e.Method + " " + e.Path
+}
+
+// Build generates the document of endpoints, the schemas of request and
+// response bodies being derived from their Go types. errorBody is the value
+// rendered for error statuses.
+func Build(info Info, endpoints []Endpoint, errorBody any) *Document {
+	gen := newGenerator()
+	errorSchema := gen.schema(errorBody)
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   make(map[string]map[string]*Operation),
+		Components: Components{
+			Schemas: gen.components,
+			SecuritySchemes: map[string]SecurityScheme{
+				bearerScheme: {Type: "http", Scheme: "bearer"},
+			},
+		},
+	}
+
+	for _, endpoint := range endpoints {
+		op := &Operation{
+			Summary:    endpoint.Summary,
+			Tags:       []string{tag(endpoint.Path)},
+			Parameters: endpoint.Params,
+			Responses:  make(map[string]*Response),
+		}
+
+		if endpoint.Body != nil {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  jsonContent(gen.schema(endpoint.Body)),
+			}
+		}
+
+		success := &Response{Description: http.StatusText(endpoint.Status)}
+		if endpoint.Response != nil {
+			success.Content = jsonContent(gen.schema(endpoint.Response))
+		}
+		op.Responses[strconv.Itoa(endpoint.Status)] = success
+		if endpoint.Cached {
+			op.Responses[strconv.Itoa(http.StatusNotModified)] = &Response{
+				Description: "Not Modified, the ETag or Last-Modified date of the request is still current",
+			}
+		}
+
+		// Every route is rate limited and bounded by a query deadline
+		statuses := append(slices.Clone(endpoint.Errors), http.StatusTooManyRequests, http.StatusGatewayTimeout)
+		if endpoint.Scope != "" {
+			op.Description = "Requires scope " + endpoint.Scope
+			op.Security = []map[string][]string{{bearerScheme: {}}}
+			statuses = append([]int{http.StatusUnauthorized, http.StatusForbidden}, statuses...)
+		}
+		for _, status := range statuses {
+			op.Responses[strconv.Itoa(status)] = &Response{
+				Description: http.StatusText(status),
+				Content:     jsonContent(errorSchema),
+			}
+		}
+
+		if doc.Paths[endpoint.Path] == nil {
+			doc.Paths[endpoint.Path] = make(map[string]*Operation)
+		}
+		doc.Paths[endpoint.Path][strings.ToLower(endpoint.Method)] = op
+	}
+
+	return doc
+}
+
+func jsonContent(schema *Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: schema}}
+}
+
+// tag groups operations by the first segment of their path
+func tag(path string) string {
+	segment, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	return segment
+}
+
+// QueryInt documents an integer query parameter bounded by [min, max]
+func QueryInt(name, description string, defaultValue, min, max int) Parameter {
+	return Parameter{
+		Name:        name,
+		In:          "query",
+		Description: description,
+		Schema: &Schema{
+			Type:    "integer",
+			Default: defaultValue,
+			Minimum: &min,
+			Maximum: &max,
+		},
+	}
+}
+
+// QueryEnum documents a string query parameter restricted to values
+func QueryEnum(name, description, defaultValue string, values ...string) Parameter {
+	return Parameter{
+		Name:        name,
+		In:          "query",
+		Description: description,
+		Schema: &Schema{
+			Type:    "string",
+			Default: defaultValue,
+			Enum:    values,
+		},
+	}
+}
+
+// QueryID documents an optional positive integer query parameter
+func QueryID(name, description string) Parameter {
+	min := 1
+	return Parameter{
+		Name:        name,
+		In:          "query",
+		Description: description,
+		Schema:      &Schema{Type: "integer", Minimum: &min},
+	}
+}
+
+// PathString documents a string path parameter
+func PathString(name, description string) Parameter {
+	return Parameter{
+		Name:        name,
+		In:          "path",
+		Description: description,
+		Required:    true,
+		Schema:      &Schema{Type: "string"},
+	}
+}
+
+// PathInt documents a positive integer path parameter
+func PathInt(name, description string) Parameter {
+	min := 1
+	return Parameter{
+		Name:        name,
+		In:          "path",
+		Description: description,
+		Required:    true,
+		Schema:      &Schema{Type: "integer", Minimum: &min},
+	}
+}
@@ -0,0 +1,101 @@
+package openapi
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testCard struct {
+	ID string `json:"id"`
+}
+
+type testBase struct {
+	CreatedAt time.Time `json:"created_at,omitempty"`
+}
+
+type testDeck struct {
+	*testBase
+	Signature string         `json:"signature"`
+	Cards     [8]testCard    `json:"cards"`
+	Best      *testCard      `json:"best"`
+	Counts    map[string]int `json:"counts,omitempty"`
+	Payload   []byte         `json:"payload,omitempty"`
+	Ignored   string         `json:"-"`
+	hidden    string
+	Nested    struct{ N int64 }  `json:"nested"`
+	Rates     []float64          `json:"rates"`
+	Extra     map[string]*string `json:"extra,omitempty"`
+}
+
+func TestGeneratorStruct(t *testing.T) {
+	gen := newGenerator()
+	ref := gen.schema(testDeck{})
+
+	if ref.Ref != "#/components/schemas/TestDeck" {
+		t.Fatalf("ref = %q, want component TestDeck", ref.Ref)
+	}
+	deck := gen.components["TestDeck"]
+
+	wantRequired := []string{"signature", "cards", "best", "nested", "rates"}
+	if !reflect.DeepEqual(deck.Required, wantRequired) {
+		t.Errorf("required = %v, want %v", deck.Required, wantRequired)
+	}
+
+	if got := deck.Properties["created_at"]; got == nil || got.Format != "date-time" {
+		t.Errorf("embedded created_at = %+v, want date-time string", got)
+	}
+	for _, name := range []string{"Ignored", "hidden"} {
+		if _, ok := deck.Properties[name]; ok {
+			t.Errorf("property %s should be skipped", name)
+		}
+	}
+
+	cards := deck.Properties["cards"]
+	if cards.Type != "array" || *cards.MinItems != 8 || *cards.MaxItems != 8 {
+		t.Errorf("cards = %+v, want array of exactly 8 items", cards)
+	}
+	if cards.Items.Ref != "#/components/schemas/TestCard" {
+		t.Errorf("cards items = %+v, want TestCard reference", cards.Items)
+	}
+
+	best := deck.Properties["best"]
+	if !best.Nullable || len(best.AllOf) != 1 || best.AllOf[0].Ref == "" {
+		t.Errorf("best = %+v, want nullable allOf reference", best)
+	}
+
+	if got := deck.Properties["payload"]; got.Type != "string" || got.Format != "byte" {
+		t.Errorf("payload = %+v, want base64 string", got)
+	}
+	if got := deck.Properties["counts"]; got.Type != "object" || got.AdditionalProperties.Type != "integer" {
+		t.Errorf("counts = %+v, want map of integers", got)
+	}
+	if got := deck.Properties["nested"]; got.Ref != "" || got.Properties["N"].Format != "int64" {
+		t.Errorf("nested = %+v, want inline object", got)
+	}
+}
+
+type collectionStats struct {
+	Total int `json:"total"`
+}
+
+func TestGeneratorNameCollision(t *testing.T) {
+	type CollectionStats struct {
+		Runs int `json:"runs"`
+	}
+
+	gen := newGenerator()
+	first := gen.schema(collectionStats{})
+	second := gen.schema(CollectionStats{})
+	again := gen.schema(collectionStats{})
+
+	if first.Ref == second.Ref {
+		t.Fatalf("distinct types share component %s", first.Ref)
+	}
+	if first.Ref != again.Ref {
+		t.Errorf("same type registered twice: %s and %s", first.Ref, again.Ref)
+	}
+	if len(gen.components) != 2 {
+		t.Errorf("components = %d, want 2", len(gen.components))
+	}
+}
@@ -0,0 +1,102 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Rate is a number of requests allowed per period
+type Rate struct {
+	Requests int
+	Period   time.Duration
+}
+
+// PerMinute returns a rate of requests per minute
+func PerMinute(requests int) Rate {
+	return Rate{Requests: requests, Period: time.Minute}
+}
+
+// Decision is the outcome of a request against a rate
+type Decision struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	Reset     time.Duration // until the quota is restored
+}
+
+// Limiter counts requests per key. MemoryLimiter only counts the requests of
+// this process, an implementation backed by a shared store is needed to
+// enforce limits across several API replicas.
+type Limiter interface {
+	Allow(ctx context.Context, key string, rate Rate) (Decision, error)
+}
+
+type window struct {
+	start time.Time
+	count int
+}
+
+// sweepEvery is the number of calls between two removals of expired windows
+const sweepEvery = 1000
+
+// MemoryLimiter is an in-process fixed window limiter
+type MemoryLimiter struct {
+	mu        sync.Mutex
+	windows   map[string]*window
+	calls     int
+	maxPeriod time.Duration // longest period in use, windows older than it are expired
+	now       func() time.Time
+}
+
+var _ Limiter = (*MemoryLimiter)(nil)
+
+// NewMemoryLimiter creates an in-process limiter
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		windows: make(map[string]*window),
+		now:     time.Now,
+	}
+}
+
+// Allow counts a request of key, refused once rate.Requests requests were
+// allowed in the current window
+func (l *MemoryLimiter) Allow(ctx context.Context, key string, rate Rate) (Decision, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.maxPeriod = max(l.maxPeriod, rate.Period)
+	l.calls++
+	if l.calls%sweepEvery == 0 {
+		l.sweep(now)
+	}
+
+	w, ok := l.windows[key]
+	if !ok || now.Sub(w.start) >= rate.Period {
+		w = &window{start: now}
+		l.windows[key] = w
+	}
+
+	decision := Decision{
+		Limit: rate.Requests,
+		Reset: w.start.Add(rate.Period).Sub(now),
+	}
+	if w.count >= rate.Requests {
+		return decision, nil
+	}
+
+	w.count++
+	decision.Allowed = true
+	decision.Remaining = rate.Requests - w.count
+	return decision, nil
+}
+
+// sweep removes the expired windows
+func (l *MemoryLimiter) sweep(now time.Time) {
+	for key, w := range l.windows {
+		if now.Sub(w.start) >= l.maxPeriod {
+			delete(l.windows, key)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryLimiter_Allow(t *testing.T) {
+	now := time.Date(2026, 2, 16, 12, 0, 0, 0, time.UTC)
+	limiter := NewMemoryLimiter()
+	limiter.now = func() time.Time { return now }
+	rate := PerMinute(3)
+	ctx := context.Background()
+
+	for i := 1; i <= 3; i++ {
+		decision, _ := limiter.Allow(ctx, "client:a", rate)
+		if !decision.Allowed || decision.Remaining != 3-i {
+			t.Fatalf("request %d: %+v, want allowed with %d remaining", i, decision, 3-i)
+		}
+	}
+
+	now = now.Add(20 * time.Second)
+	decision, _ := limiter.Allow(ctx, "client:a", rate)
+	if decision.Allowed || decision.Remaining != 0 {
+		t.Fatalf("4th request: %+v, want refused", decision)
+	}
+	if decision.Reset != 40*time.Second {
+		t.Errorf("reset = %v, want 40s", decision.Reset)
+	}
+
+	if decision, _ := limiter.Allow(ctx, "client:b", rate); !decision.Allowed {
+		t.Error("other key should have its own quota")
+	}
+
+	now = now.Add(40 * time.Second)
+	if decision, _ := limiter.Allow(ctx, "client:a", rate); !decision.Allowed || decision.Remaining != 2 {
+		t.Errorf("after the window: %+v, want allowed with 2 remaining", decision)
+	}
+}
+
+func TestMemoryLimiter_Sweep(t *testing.T) {
+	now := time.Date(2026, 2, 16, 12, 0, 0, 0, time.UTC)
+	limiter := NewMemoryLimiter()
+	limiter.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	limiter.Allow(ctx, "ip:old", PerMinute(10))
+	now = now.Add(2 * time.Minute)
+	for i := 1; i < sweepEvery; i++ {
+		limiter.Allow(ctx, "ip:new", PerMinute(10_000))
+	}
+
+	if _, ok := limiter.windows["ip:old"]; ok {
+		t.Error("expired window not swept")
+	}
+	if _, ok := limiter.windows["ip:new"]; !ok {
+		t.Error("active window swept")
+	}
+}
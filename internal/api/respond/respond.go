@@ -0,0 +1,120 @@
+package respond
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+
+	"github.com/leopoldhub/royal-api-personal/internal/errors"
+	"github.com/leopoldhub/royal-api-personal/internal/models"
+	"github.com/lib/pq"
+)
+
+// Error codes of the error envelope
+const (
+	CodeInvalidParameter = "invalid_parameter"
+	CodeInvalidBody      = "invalid_body"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeNotFound         = "not_found"
+	CodeRateLimited      = "rate_limited"
+	CodeTimeout          = "timeout"
+	CodeInternal         = "internal_error"
+)
+
+// StatusClientClosedRequest is logged for requests whose client disconnected
+// before the response, following the nginx convention
+const StatusClientClosedRequest = 499
+
+type requestIDKey struct{}
+
+type clientKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID of ctx, empty when unset
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// WithClient returns a copy of ctx carrying the authenticated API client
+func WithClient(ctx context.Context, client *models.APIClient) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+// Client returns the authenticated API client of ctx, nil on public routes
+func Client(ctx context.Context) *models.APIClient {
+	client, _ := ctx.Value(clientKey{}).(*models.APIClient)
+	return client
+}
+
+// ErrorBody describes a failed request
+type ErrorBody struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Field     string `json:"field,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// ErrorEnvelope is the body of every error response
+type ErrorEnvelope struct {
+	Error ErrorBody `json:"error"`
+}
+
+// Error writes the error envelope {"error": {code, message, field, request_id}}
+func Error(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	write(w, status, ErrorBody{
+		Code:      code,
+		Message:   message,
+		RequestID: RequestID(r.Context()),
+	})
+}
+
+// Invalid writes a 400 error envelope naming the invalid field
+func Invalid(w http.ResponseWriter, r *http.Request, err *errors.ValidationError) {
+	write(w, http.StatusBadRequest, ErrorBody{
+		Code:      CodeInvalidParameter,
+		Message:   err.Message,
+		Field:     err.Field,
+		RequestID: RequestID(r.Context()),
+	})
+}
+
+// Failure writes the error envelope of a failed operation: 504 when the query
+// deadline of the route expired, 500 with message otherwise. Nothing is sent
+// when the client disconnected, the status only being recorded in the logs.
+// The request context decides, since the driver reports a canceled query with
+// its own error rather than the context one.
+func Failure(w http.ResponseWriter, r *http.Request, err error, message string) {
+	switch ctxErr := r.Context().Err(); {
+	case stderrors.Is(ctxErr, context.DeadlineExceeded):
+		Error(w, r, http.StatusGatewayTimeout, CodeTimeout, "query deadline exceeded")
+	case ctxErr != nil:
+		w.WriteHeader(StatusClientClosedRequest)
+	case stderrors.Is(err, context.DeadlineExceeded) || queryCanceled(err):
+		Error(w, r, http.StatusGatewayTimeout, CodeTimeout, "query deadline exceeded")
+	default:
+		Error(w, r, http.StatusInternalServerError, CodeInternal, message)
+	}
+}
+
+// queryCanceledCode is the SQLSTATE of a query canceled by the server, on
+// statement_timeout or on a cancel request
+const queryCanceledCode = "57014"
+
+func queryCanceled(err error) bool {
+	var pqErr *pq.Error
+	return stderrors.As(err, &pqErr) && pqErr.Code == queryCanceledCode
+}
+
+func write(w http.ResponseWriter, status int, body ErrorBody) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorEnvelope{Error: body})
+}
@@ -0,0 +1,53 @@
+package respond
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/leopoldhub/royal-api-personal/internal/errors"
+	"github.com/lib/pq"
+)
+
+func TestFailure(t *testing.T) {
+	// lib/pq reports a query canceled on context cancellation as a server error
+	canceledQuery := &errors.DBError{
+		Operation: "query",
+		Table:     "battles",
+		Err:       &pq.Error{Code: "57014", Message: "canceling statement due to user request"},
+	}
+
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+	disconnected, disconnect := context.WithCancel(context.Background())
+	disconnect()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want int
+	}{
+		{"route deadline expired", expired, canceledQuery, http.StatusGatewayTimeout},
+		{"client disconnected", disconnected, canceledQuery, StatusClientClosedRequest},
+		{"statement timeout", context.Background(), canceledQuery, http.StatusGatewayTimeout},
+		{"context deadline error", context.Background(), fmt.Errorf("query: %w", context.DeadlineExceeded), http.StatusGatewayTimeout},
+		{"other error", context.Background(), fmt.Errorf("connection refused"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/decks/meta", nil).WithContext(tt.ctx)
+
+			Failure(rec, req, tt.err, "failed to fetch meta decks")
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
@@ -1,35 +1,94 @@
 package api
 
 import (
+	"context"
 	"database/sql"
+	stderrors "errors"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"time"
 
+	"github.com/leopoldhub/royal-api-personal/internal/api/cache"
 	"github.com/leopoldhub/royal-api-personal/internal/api/handlers"
 	"github.com/leopoldhub/royal-api-personal/internal/api/middleware"
+	"github.com/leopoldhub/royal-api-personal/internal/api/ratelimit"
+	"github.com/leopoldhub/royal-api-personal/internal/auth"
 	"github.com/leopoldhub/royal-api-personal/internal/database/repository"
+	"github.com/leopoldhub/royal-api-personal/internal/metrics"
+	"github.com/leopoldhub/royal-api-personal/internal/models"
 )
 
+const (
+	// cacheEntries bounds the responses kept by the response cache
+	cacheEntries = 1000
+	// cacheMaxAge is how long clients may reuse a response without revalidating
+	cacheMaxAge = time.Minute
+
+	// queryTimeout bounds the queries of a request, see slowRoutes
+	queryTimeout = 5 * time.Second
+
+	readHeaderTimeout = 5 * time.Second
+	readTimeout       = 10 * time.Second
+	// writeTimeout leaves the slowest route time to render its response
+	writeTimeout = 40 * time.Second
+	idleTimeout  = 2 * time.Minute
+	// shutdownTimeout bounds the draining of in-flight requests on shutdown
+	shutdownTimeout = 45 * time.Second
+)
+
+// slowRoutes overrides queryTimeout for routes aggregating or tagging battles over days
+var slowRoutes = map[string]time.Duration{
+	"GET /decks/trending":            20 * time.Second,
+	"GET /decks/{signature}/history": 20 * time.Second,
+	"GET /patches/{id}/compare":      20 * time.Second,
+	"POST /patches":                  30 * time.Second,
+	"DELETE /patches/{id}":           30 * time.Second,
+}
+
 // Server represents the HTTP API server
 type Server struct {
-	db       *sql.DB
-	apiToken string
-	router   *http.ServeMux
-	logger   *log.Logger
+	db            *sql.DB
+	apiToken      string // bootstrap admin token
+	authenticator *auth.Authenticator
+	limiter       ratelimit.Limiter
+	rateLimits    map[string]int // scope, empty for public routes -> requests per minute
+	cache         *cache.Store
+	versions      middleware.VersionSource
+	router        *http.ServeMux
+	routes        map[string]string // registered pattern -> required scope, empty for public routes
+	metrics       *metrics.Registry // HTTP and database pool metrics of this server
+	httpMetrics   *middleware.HTTPMetrics
+	cached        map[string]bool // registered patterns served from the response cache
+	logger        *log.Logger
 }
 
-// NewServer creates a new API server
-func NewServer(db *sql.DB, apiToken string, logger *log.Logger) *Server {
+// NewServer creates a new API server. rateLimits maps a route scope, empty for
+// public routes, to the requests per minute allowed per client or IP address,
+// scopes without a positive limit being unlimited. models.RateLimitAuth limits
+// the requests per IP address on protected routes before the token is checked.
+func NewServer(db *sql.DB, apiToken string, rateLimits map[string]int, logger *log.Logger) *Server {
 	if logger == nil {
 		logger = log.Default()
 	}
 
 	s := &Server{
-		db:       db,
-		apiToken: apiToken,
-		router:   http.NewServeMux(),
-		logger:   logger,
+		db:         db,
+		apiToken:   apiToken,
+		router:     http.NewServeMux(),
+		routes:     make(map[string]string),
+		cached:     make(map[string]bool),
+		cache:      cache.NewStore(cacheEntries),
+		metrics:    metrics.NewRegistry(),
+		limiter:    ratelimit.NewMemoryLimiter(),
+		rateLimits: rateLimits,
+		logger:     logger,
+	}
+
+	s.httpMetrics = middleware.NewHTTPMetrics(s.metrics)
+	if db != nil {
+		metrics.RegisterDBStats(s.metrics, db)
 	}
 
 	s.setupRoutes()
@@ -39,30 +98,126 @@ func NewServer(db *sql.DB, apiToken string, logger *log.Logger) *Server {
 func (s *Server) setupRoutes() {
 	battleRepo := repository.NewBattleRepository(s.db)
 	metaRepo := repository.NewMetaDeckRepository(s.db)
+	statsRepo := repository.NewCollectionStatsRepository(s.db)
+	rejectedRepo := repository.NewRejectedBattleRepository(s.db)
+	historyRepo := repository.NewMetaHistoryRepository(s.db)
+	patchRepo := repository.NewPatchRepository(s.db)
+	clientRepo := repository.NewAPIClientRepository(s.db)
+
+	s.authenticator = auth.NewAuthenticator(clientRepo, s.apiToken)
+	s.versions = repository.NewDataVersionRepository(s.db)
+
+	docsHandler := handlers.NewDocsHandler(handlers.Endpoints)
+	healthHandler := handlers.NewHealthHandler(s.db, battleRepo, metaRepo, statsRepo)
+	deckHandler := handlers.NewDeckHandler(battleRepo, metaRepo, historyRepo)
+	patchHandler := handlers.NewPatchHandler(patchRepo)
+	statsHandler := handlers.NewStatsHandler(battleRepo, metaRepo, statsRepo, rejectedRepo)
+	clientHandler := handlers.NewClientHandler(clientRepo)
+
+	s.public("GET /health", healthHandler.Handle)
+	s.public("GET /openapi.json", docsHandler.GetSpec)
+	s.public("GET /docs", docsHandler.GetDocs)
+
+	s.cacheable("GET /decks/meta", models.ScopeReadDecks, deckHandler.GetMetaDecks)
+	s.cacheable("GET /decks/trending", models.ScopeReadDecks, deckHandler.GetTrendingDecks)
+	s.cacheable("GET /decks/{signature}", models.ScopeReadDecks, deckHandler.GetDeckBySignature)
+	s.cacheable("GET /decks/{signature}/history", models.ScopeReadDecks, deckHandler.GetDeckHistory)
+	s.cacheable("GET /patches", models.ScopeReadDecks, patchHandler.ListPatches)
+	s.protected("POST /patches", models.ScopeAdmin, patchHandler.CreatePatch)
+	s.protected("DELETE /patches/{id}", models.ScopeAdmin, patchHandler.DeletePatch)
+	s.cacheable("GET /patches/{id}/compare", models.ScopeReadDecks, patchHandler.ComparePatch)
+	s.cacheable("GET /stats/summary", models.ScopeReadStats, statsHandler.GetSummary)
+	s.cacheable("GET /stats/collections", models.ScopeReadStats, statsHandler.GetCollections)
+	s.cacheable("GET /stats/rejections", models.ScopeReadStats, statsHandler.GetRejections)
+	s.protected("GET /clients", models.ScopeAdmin, clientHandler.ListClients)
+	s.protected("POST /clients", models.ScopeAdmin, clientHandler.CreateClient)
+	s.protected("DELETE /clients/{id}", models.ScopeAdmin, clientHandler.RevokeClient)
+	s.protected("GET /metrics", models.ScopeReadMetrics, metrics.Handler(s.metrics, metrics.Default).ServeHTTP)
+}
+
+// public registers a route rate limited per IP address
+func (s *Server) public(pattern string, handler http.HandlerFunc) {
+	s.handle(pattern, "", s.rateLimited("", handler))
+}
 
-	healthHandler := handlers.NewHealthHandler(s.db, battleRepo, metaRepo)
-	deckHandler := handlers.NewDeckHandler(battleRepo, metaRepo)
-	statsHandler := handlers.NewStatsHandler(battleRepo, metaRepo)
+// protected registers a route requiring a Bearer token granted scope, rate
+// limited per IP address before authentication and per client after it
+func (s *Server) protected(pattern, scope string, handler http.HandlerFunc) {
+	authenticated := middleware.Auth(s.authenticator, scope)(s.rateLimited(scope, handler))
+	s.handle(pattern, scope, s.rateLimited(models.RateLimitAuth, authenticated))
+}
 
-	s.router.HandleFunc("GET /health", healthHandler.Handle)
+// handle registers a route whose queries, token lookup included, share the
+// route deadline, its requests being counted under pattern
+func (s *Server) handle(pattern, scope string, handler http.Handler) {
+	timeout, ok := slowRoutes[pattern]
+	if !ok {
+		timeout = queryTimeout
+	}
+	s.router.Handle(pattern, middleware.Metrics(s.httpMetrics, pattern)(middleware.Deadline(timeout)(handler)))
+	s.routes[pattern] = scope
+}
 
-	s.router.HandleFunc("GET /decks/meta", s.protected(deckHandler.GetMetaDecks))
-	s.router.HandleFunc("GET /decks/{signature}", s.protected(deckHandler.GetDeckBySignature))
-	s.router.HandleFunc("GET /stats/summary", s.protected(statsHandler.GetSummary))
+// cacheable registers a protected route whose responses are cached until the data version changes
+func (s *Server) cacheable(pattern, scope string, handler http.HandlerFunc) {
+	s.protected(pattern, scope, middleware.Cache(s.cache, s.versions, cacheMaxAge)(handler).ServeHTTP)
+	s.cached[pattern] = true
 }
 
-func (s *Server) protected(handler http.HandlerFunc) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		middleware.Auth(s.apiToken)(http.HandlerFunc(handler)).ServeHTTP(w, r)
+func (s *Server) rateLimited(scope string, handler http.Handler) http.Handler {
+	limit := s.rateLimits[scope]
+	if limit <= 0 {
+		return handler
 	}
+	return middleware.RateLimit(s.limiter, scope, ratelimit.PerMinute(limit))(handler)
 }
 
-// Start starts the HTTP server
-func (s *Server) Start(port int) error {
+// Start serves the API on port until ctx is canceled, then stops accepting
+// connections and drains in-flight requests. serve passes a context canceled
+// on SIGINT and SIGTERM (signal.NotifyContext).
+func (s *Server) Start(ctx context.Context, port int) error {
 	addr := fmt.Sprintf(":%d", port)
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
 	s.logger.Printf("Starting API server on %s", addr)
 
-	handler := middleware.Logging(s.logger)(middleware.JSON(s.router))
+	return s.Serve(ctx, listener)
+}
+
+// Serve serves the API on listener until ctx is canceled, see Start
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	server := &http.Server{
+		Handler:           middleware.RequestID(middleware.Logging(s.logger)(middleware.JSON(s.router))),
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       readTimeout,
+		WriteTimeout:      writeTimeout,
+		IdleTimeout:       idleTimeout,
+		ErrorLog:          s.logger,
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
 
-	return http.ListenAndServe(addr, handler)
+	s.logger.Printf("Shutting down API server, draining in-flight requests")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		server.Close()
+		return fmt.Errorf("failed to drain requests: %w", err)
+	}
+	if err := <-errc; !stderrors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
 }
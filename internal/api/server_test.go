@@ -0,0 +1,285 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leopoldhub/royal-api-personal/internal/api/handlers"
+	"github.com/leopoldhub/royal-api-personal/internal/models"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/openapi.json from the handlers")
+
+const specGolden = "openapi.json"
+
+func newTestServer() *Server {
+	return NewServer(nil, "test_token", nil, log.New(io.Discard, "", 0))
+}
+
+func TestRoutesDocumented(t *testing.T) {
+	s := newTestServer()
+
+	documented := make(map[string]bool)
+	for _, endpoint := range handlers.Endpoints {
+		pattern := endpoint.Pattern()
+		if documented[pattern] {
+			t.Errorf("route %s documented twice", pattern)
+		}
+		documented[pattern] = true
+
+		scope, ok := s.routes[pattern]
+		if !ok {
+			t.Errorf("route %s is documented but not registered", pattern)
+			continue
+		}
+		if scope != endpoint.Scope {
+			t.Errorf("route %s requires scope %q, documented %q", pattern, scope, endpoint.Scope)
+		}
+		if s.cached[pattern] != endpoint.Cached {
+			t.Errorf("route %s cached = %v, documented %v", pattern, s.cached[pattern], endpoint.Cached)
+		}
+	}
+
+	for pattern := range s.routes {
+		if !documented[pattern] {
+			t.Errorf("route %s is registered but missing from handlers.Endpoints", pattern)
+		}
+	}
+}
+
+// TestOpenAPISpecUpToDate fails when a route or a response struct changes
+// without the reviewed spec being regenerated with go test ./internal/api -update
+func TestOpenAPISpecUpToDate(t *testing.T) {
+	got, err := json.MarshalIndent(handlers.Spec(handlers.Endpoints), "", "  ")
+	if err != nil {
+		t.Fatalf("failed to marshal spec: %v", err)
+	}
+	got = append(got, '\n')
+
+	path := filepath.Join("testdata", specGolden)
+	if *update {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("OpenAPI spec drifted from %s, review the API change and run go test ./internal/api -update", path)
+	}
+}
+
+func TestOpenAPIServed(t *testing.T) {
+	s := newTestServer()
+
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var spec struct {
+		OpenAPI string                    `json:"openapi"`
+		Paths   map[string]map[string]any `json:"paths"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&spec); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if spec.OpenAPI == "" {
+		t.Error("openapi version missing")
+	}
+	if _, ok := spec.Paths["/decks/{signature}"]["get"]; !ok {
+		t.Error("GET /decks/{signature} missing from served spec")
+	}
+}
+
+func TestProtectedRoutes_RequireToken(t *testing.T) {
+	s := newTestServer()
+
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/decks/meta", nil))
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	var body struct {
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if body.Error.Code != "unauthorized" {
+		t.Errorf("code = %q, want unauthorized", body.Error.Code)
+	}
+}
+
+func TestPublicRoutes_RateLimited(t *testing.T) {
+	s := NewServer(nil, "test_token", map[string]int{"": 2}, log.New(io.Discard, "", 0))
+
+	var rec *httptest.ResponseRecorder
+	for i := 0; i < 3; i++ {
+		rec = httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+		req.RemoteAddr = "203.0.113.7:51234"
+		s.router.ServeHTTP(rec, req)
+	}
+
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+	if got := rec.Header().Get("RateLimit-Limit"); got != "2" {
+		t.Errorf("RateLimit-Limit = %q, want 2", got)
+	}
+	if got := rec.Header().Get("RateLimit-Remaining"); got != "0" {
+		t.Errorf("RateLimit-Remaining = %q, want 0", got)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("Retry-After missing")
+	}
+
+	other := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+	req.RemoteAddr = "198.51.100.1:40000"
+	s.router.ServeHTTP(other, req)
+	if other.Code != http.StatusOK {
+		t.Errorf("other IP status = %d, want %d", other.Code, http.StatusOK)
+	}
+}
+
+func TestProtectedRoutes_RateLimitedPerIPBeforeAuth(t *testing.T) {
+	s := NewServer(nil, "test_token", map[string]int{models.RateLimitAuth: 2}, log.New(io.Discard, "", 0))
+
+	var codes []int
+	for i := 0; i < 3; i++ {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/decks/meta", nil)
+		req.RemoteAddr = "203.0.113.7:51234"
+		s.router.ServeHTTP(rec, req)
+		codes = append(codes, rec.Code)
+	}
+
+	want := []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests}
+	if !slices.Equal(codes, want) {
+		t.Errorf("status codes = %v, want %v", codes, want)
+	}
+}
+
+func TestRoutes_QueryDeadline(t *testing.T) {
+	s := newTestServer()
+
+	var remaining time.Duration
+	s.handle("GET /deadline", "", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deadline, ok := r.Context().Deadline()
+		if !ok {
+			t.Fatal("request context has no deadline")
+		}
+		remaining = time.Until(deadline)
+	}))
+	s.router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/deadline", nil))
+
+	if remaining <= 0 || remaining > queryTimeout {
+		t.Errorf("deadline in %v, want within %v", remaining, queryTimeout)
+	}
+}
+
+func TestServe_DrainsInFlightRequests(t *testing.T) {
+	s := newTestServer()
+
+	started := make(chan struct{})
+	s.router.HandleFunc("GET /slow", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte(`{"done":true}`))
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- s.Serve(ctx, listener)
+	}()
+
+	type result struct {
+		status int
+		body   string
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String() + "/slow")
+		if err != nil {
+			done <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		done <- result{status: resp.StatusCode, body: string(body), err: err}
+	}()
+
+	<-started
+	cancel()
+
+	res := <-done
+	if res.err != nil {
+		t.Fatalf("in-flight request failed: %v", res.err)
+	}
+	if res.status != http.StatusOK || res.body != `{"done":true}` {
+		t.Errorf("response = %d %s, want 200 {\"done\":true}", res.status, res.body)
+	}
+	if err := <-served; err != nil {
+		t.Errorf("Serve returned %v, want nil after shutdown", err)
+	}
+}
+
+func TestMetricsServed(t *testing.T) {
+	s := newTestServer()
+
+	s.router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	s.router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/decks/meta", nil))
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer test_token")
+	s.router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain") {
+		t.Errorf("Content-Type = %q, want text exposition format", rec.Header().Get("Content-Type"))
+	}
+
+	body := rec.Body.String()
+	for _, want := range []string{
+		`royal_http_requests_total{route="GET /openapi.json",status="200"} 1`,
+		`royal_http_requests_total{route="GET /decks/meta",status="401"} 1`,
+		`royal_http_request_duration_seconds_count{route="GET /openapi.json"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %s", want)
+		}
+	}
+}
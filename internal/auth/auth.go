@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"time"
+
+	"github.com/leopoldhub/royal-api-personal/internal/database/repository"
+	"github.com/leopoldhub/royal-api-personal/internal/errors"
+	"github.com/leopoldhub/royal-api-personal/internal/models"
+)
+
+// tokenPrefix marks the tokens issued to API clients
+const tokenPrefix = "rap_"
+
+// BootstrapClient is the identity of the API_TOKEN, an admin used to create the first clients
+const BootstrapClient = "bootstrap"
+
+// GenerateToken returns a new random client token
+func GenerateToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return tokenPrefix + hex.EncodeToString(buf), nil
+}
+
+// HashToken returns the hex SHA-256 of token, the form tokens are stored in
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Authenticator resolves Bearer tokens into API clients
+type Authenticator struct {
+	clients       repository.APIClientRepository
+	bootstrapHash []byte
+	now           func() time.Time
+}
+
+// NewAuthenticator creates an authenticator for the stored clients and the
+// bootstrap token, which is disabled when empty
+func NewAuthenticator(clients repository.APIClientRepository, bootstrapToken string) *Authenticator {
+	a := &Authenticator{
+		clients: clients,
+		now:     time.Now,
+	}
+	if bootstrapToken != "" {
+		a.bootstrapHash = []byte(HashToken(bootstrapToken))
+	}
+	return a
+}
+
+// Authenticate returns the client owning token, or an *errors.AuthError when
+// the token is unknown, expired or revoked. Tokens are only compared through
+// their hashes, in constant time for the bootstrap token.
+func (a *Authenticator) Authenticate(ctx context.Context, token string) (*models.APIClient, error) {
+	hash := HashToken(token)
+
+	if a.bootstrapHash != nil && subtle.ConstantTimeCompare([]byte(hash), a.bootstrapHash) == 1 {
+		return &models.APIClient{
+			Name:   BootstrapClient,
+			Scopes: []string{models.ScopeAdmin},
+		}, nil
+	}
+
+	client, err := a.clients.GetByTokenHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		return nil, &errors.AuthError{Message: "invalid token"}
+	}
+	if client.RevokedAt != nil {
+		return nil, &errors.AuthError{Message: "token revoked"}
+	}
+	if client.Expired(a.now()) {
+		return nil, &errors.AuthError{Message: "token expired"}
+	}
+
+	return client, nil
+}
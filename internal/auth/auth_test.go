@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"context"
+	stderrors "errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leopoldhub/royal-api-personal/internal/errors"
+	"github.com/leopoldhub/royal-api-personal/internal/models"
+)
+
+type memoryClientRepo struct {
+	byHash map[string]*models.APIClient
+}
+
+func (r *memoryClientRepo) Create(ctx context.Context, client *models.APIClient, tokenHash string) error {
+	r.byHash[tokenHash] = client
+	return nil
+}
+
+func (r *memoryClientRepo) GetByTokenHash(ctx context.Context, tokenHash string) (*models.APIClient, error) {
+	return r.byHash[tokenHash], nil
+}
+
+func (r *memoryClientRepo) List(ctx context.Context) ([]*models.APIClient, error) {
+	return nil, nil
+}
+
+func (r *memoryClientRepo) Revoke(ctx context.Context, id int) (bool, error) {
+	return false, nil
+}
+
+func TestGenerateToken(t *testing.T) {
+	first, err := GenerateToken()
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	second, _ := GenerateToken()
+
+	if !strings.HasPrefix(first, tokenPrefix) || len(first) != len(tokenPrefix)+64 {
+		t.Errorf("token %q, want %s followed by 64 hex characters", first, tokenPrefix)
+	}
+	if first == second {
+		t.Error("two generated tokens are equal")
+	}
+	if HashToken(first) == first || len(HashToken(first)) != 64 {
+		t.Errorf("HashToken(%q) = %q, want hex SHA-256", first, HashToken(first))
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	now := time.Date(2026, 2, 9, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	repo := &memoryClientRepo{byHash: map[string]*models.APIClient{
+		HashToken("rap_valid"):   {ID: 1, Name: "frontend", Scopes: []string{models.ScopeReadDecks}, ExpiresAt: &future},
+		HashToken("rap_expired"): {ID: 2, Name: "old", Scopes: []string{models.ScopeReadDecks}, ExpiresAt: &past},
+		HashToken("rap_revoked"): {ID: 3, Name: "leaked", Scopes: []string{models.ScopeAdmin}, RevokedAt: &past},
+	}}
+	authenticator := NewAuthenticator(repo, "bootstrap_token")
+	authenticator.now = func() time.Time { return now }
+
+	tests := []struct {
+		name       string
+		token      string
+		wantClient string
+		wantErr    string
+	}{
+		{name: "bootstrap token", token: "bootstrap_token", wantClient: BootstrapClient},
+		{name: "valid client", token: "rap_valid", wantClient: "frontend"},
+		{name: "unknown token", token: "rap_unknown", wantErr: "invalid token"},
+		{name: "expired token", token: "rap_expired", wantErr: "token expired"},
+		{name: "revoked token", token: "rap_revoked", wantErr: "token revoked"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := authenticator.Authenticate(context.Background(), tt.token)
+
+			if tt.wantErr != "" {
+				var authErr *errors.AuthError
+				if !stderrors.As(err, &authErr) || authErr.Message != tt.wantErr {
+					t.Fatalf("Authenticate() error = %v, want AuthError %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authenticate() error = %v", err)
+			}
+			if client.Name != tt.wantClient {
+				t.Errorf("client = %s, want %s", client.Name, tt.wantClient)
+			}
+		})
+	}
+}
+
+func TestAuthenticate_BootstrapDisabled(t *testing.T) {
+	authenticator := NewAuthenticator(&memoryClientRepo{byHash: map[string]*models.APIClient{}}, "")
+
+	if _, err := authenticator.Authenticate(context.Background(), ""); err == nil {
+		t.Error("empty token accepted while the bootstrap token is disabled")
+	}
+}
+
+func TestHasScope(t *testing.T) {
+	reader := &models.APIClient{Scopes: []string{models.ScopeReadDecks}}
+	admin := &models.APIClient{Scopes: []string{models.ScopeAdmin}}
+
+	if !reader.HasScope(models.ScopeReadDecks) || reader.HasScope(models.ScopeReadStats) {
+		t.Error("read:decks client should only be granted read:decks")
+	}
+	for _, scope := range models.Scopes {
+		if !admin.HasScope(scope) {
+			t.Errorf("admin client should be granted %s", scope)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package collector
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/leopoldhub/royal-api-personal/internal/models"
+	"github.com/leopoldhub/royal-api-personal/pkg/supercell"
+)
+
+// reparsePageSize is the number of archived battlelogs loaded at once by Reparse
+const reparsePageSize = 100
+
+// ReparseResult contains statistics about a reparse run
+type ReparseResult struct {
+	Battlelogs     int
+	BattlesParsed  int
+	BattlesDeleted int64          // stored battles replaced by the new parser output
+	BattlesStored  int            // battles inserted from the archive
+	Rejections     map[string]int // parser rejections per reason
+	Errors         []error
+	Duration       time.Duration
+}
+
+// archiveBattlelog compresses a fetched battlelog, keeping each battle as received
+func archiveBattlelog(playerTag string, battles []supercell.BattleRaw, fetchedAt time.Time) (*models.RawBattlelog, error) {
+	content, err := json.Marshal(battles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode battlelog of %s: %w", playerTag, err)
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(content); err != nil {
+		return nil, fmt.Errorf("failed to compress battlelog of %s: %w", playerTag, err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress battlelog of %s: %w", playerTag, err)
+	}
+
+	return &models.RawBattlelog{
+		PlayerTag:   playerTag,
+		FetchedAt:   fetchedAt,
+		BattleCount: len(battles),
+		Payload:     buf.Bytes(),
+	}, nil
+}
+
+// unarchiveBattlelog decompresses an archived battlelog
+func unarchiveBattlelog(battlelog *models.RawBattlelog) ([]supercell.BattleRaw, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(battlelog.Payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress battlelog %d: %w", battlelog.ID, err)
+	}
+	defer zr.Close()
+
+	content, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress battlelog %d: %w", battlelog.ID, err)
+	}
+
+	var battles []supercell.BattleRaw
+	if err := json.Unmarshal(content, &battles); err != nil {
+		return nil, fmt.Errorf("failed to decode battlelog %d: %w", battlelog.ID, err)
+	}
+	return battles, nil
+}
+
+// Reparse re-runs the current parser over the archived battlelogs, replaces the
+// battles they cover, then recalculates meta deck statistics and the daily meta
+// history of the days replaced.
+func (c *CollectorService) Reparse(ctx context.Context) (*ReparseResult, error) {
+	if c.archiveRepo == nil {
+		return nil, fmt.Errorf("raw battlelog archive is not enabled")
+	}
+
+	started := time.Now()
+	result := &ReparseResult{
+		Errors:     make([]error, 0),
+		Rejections: make(map[string]int),
+	}
+
+	c.preparePartitions(ctx)
+
+	var replaced timeSpan
+	var afterID int64
+	for {
+		battlelogs, err := c.archiveRepo.List(ctx, afterID, reparsePageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list archived battlelogs: %w", err)
+		}
+		if len(battlelogs) == 0 {
+			break
+		}
+
+		for _, battlelog := range battlelogs {
+			afterID = battlelog.ID
+			if err := c.reparseBattlelog(ctx, battlelog, result, &replaced); err != nil {
+				result.Errors = append(result.Errors, err)
+			}
+		}
+		c.logger.Printf("Progress: %d battlelogs reparsed", result.Battlelogs)
+	}
+
+	c.logger.Printf("Reparsed %d battlelogs: %d battles parsed, %d replaced, %d stored",
+		result.Battlelogs, result.BattlesParsed, result.BattlesDeleted, result.BattlesStored)
+	for reason, count := range result.Rejections {
+		c.logger.Printf("Rejected %d battles: %s", count, reason)
+	}
+	if len(result.Errors) > 0 {
+		c.logger.Printf("Warning: %d battlelogs not replaced, their previous battles are kept", len(result.Errors))
+	}
+
+	if err := c.metaRepo.Recalculate(ctx); err != nil {
+		return nil, fmt.Errorf("failed to recalculate meta stats: %w", err)
+	}
+	c.logger.Println("Recalculated meta deck statistics")
+
+	if c.historyRepo != nil && replaced.ok {
+		rows, err := c.historyRepo.Rebuild(ctx, replaced.from, replaced.to, c.retentionDays)
+		if err != nil {
+			return nil, fmt.Errorf("failed to rebuild meta history: %w", err)
+		}
+		c.logger.Printf("Rebuilt meta history from %s to %s (%d deck rows)",
+			replaced.from.Format("2006-01-02"), replaced.to.Format("2006-01-02"), rows)
+	}
+
+	result.Duration = time.Since(started)
+	return result, nil
+}
+
+// reparseBattlelog replaces the stored battles of a player within the time span of
+// an archived battlelog. The battlelog holds every battle of the player in that
+// span, so battles the parser now rejects disappear and the others are rebuilt.
+// The replacement is atomic: the previous battles are kept when it fails.
+func (c *CollectorService) reparseBattlelog(
+	ctx context.Context,
+	battlelog *models.RawBattlelog,
+	result *ReparseResult,
+	replaced *timeSpan,
+) error {
+	battles, err := unarchiveBattlelog(battlelog)
+	if err != nil {
+		return err
+	}
+	result.Battlelogs++
+
+	filtered := FilterPvPLadder(battles)
+	parsed, rejected := c.parseBattles(battlelog.PlayerTag, filtered)
+	result.BattlesParsed += len(parsed)
+	// Rejections were already stored as dead letters during the collection
+	for _, battle := range rejected {
+		result.Rejections[battle.Reason]++
+	}
+
+	from, to, ok := battleTimeSpan(battles)
+	if !ok {
+		return nil
+	}
+
+	deleted, summary, err := c.battleRepo.ReplacePlayerRange(ctx, battlelog.PlayerTag, from, to, parsed)
+	if err != nil {
+		return fmt.Errorf("failed to replace battles of %s: %w", battlelog.PlayerTag, err)
+	}
+	result.BattlesDeleted += deleted
+	result.BattlesStored += summary.Inserted
+	replaced.add(from, to)
+	return nil
+}
+
+// timeSpan is the smallest time range covering the added ranges
+type timeSpan struct {
+	from, to time.Time
+	ok       bool
+}
+
+func (s *timeSpan) add(from, to time.Time) {
+	if !s.ok || from.Before(s.from) {
+		s.from = from
+	}
+	if !s.ok || to.After(s.to) {
+		s.to = to
+	}
+	s.ok = true
+}
+
+// battleTimeSpan returns the oldest and newest valid battle times of a battlelog
+func battleTimeSpan(battles []supercell.BattleRaw) (time.Time, time.Time, bool) {
+	var from, to time.Time
+	for _, raw := range battles {
+		battleTime, err := ParseBattleTime(raw.BattleTime)
+		if err != nil {
+			continue
+		}
+		if from.IsZero() || battleTime.Before(from) {
+			from = battleTime
+		}
+		if battleTime.After(to) {
+			to = battleTime
+		}
+	}
+	return from, to, !from.IsZero()
+}
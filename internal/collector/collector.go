@@ -2,6 +2,8 @@ package collector
 
 import (
 	"context"
+	"encoding/json"
+	stderrors "errors"
 	"fmt"
 	"log"
 	"sync"
@@ -13,54 +15,193 @@ import (
 	"github.com/leopoldhub/royal-api-personal/pkg/supercell"
 )
 
+// DefaultRetentionDays is the number of days battles are kept when not configured
+const DefaultRetentionDays = 7
+
+// partitionsAhead is the number of days of battles partitions created in advance
+const partitionsAhead = 7
+
+// ErrCollectionRunning is returned when another process is running a collection
+var ErrCollectionRunning = stderrors.New("another collection is running")
+
 // CollectorService implements the Service interface
 type CollectorService struct {
 	supercellClient supercell.Client
 	battleRepo      repository.BattleRepository
 	metaRepo        repository.MetaDeckRepository
+	scheduleRepo    repository.PlayerScheduleRepository
+	statsRepo       repository.CollectionStatsRepository
+	rejectedRepo    repository.RejectedBattleRepository
+	archiveRepo     repository.RawBattlelogRepository
+	historyRepo     repository.MetaHistoryRepository
+	batchSize       int
 	limit           int
+	retentionDays   int
+	lastPurge       time.Time // last successful rollup and purge, see purge
+	lastPartitions  time.Time // last successful partition creation, see preparePartitions
+	noSchedules     sync.Once // warns once that scheduleRepo is nil
 	logger          *log.Logger
 }
 
 var _ Service = (*CollectorService)(nil)
 
-// NewService creates a new collector service
+// NewService creates a new collector service.
+// scheduleRepo may be nil, in which case adaptive polling and gap detection are
+// disabled and CollectDue behaves like Collect. statsRepo may be nil to skip recording runs and
+// rejectedRepo may be nil to only count parser rejections without storing them.
+// archiveRepo may be nil to disable the raw battlelog archive used by Reparse.
+// historyRepo may be nil to purge battles without rolling them up into the daily
+// history first. retentionDays below 1 falls back to DefaultRetentionDays.
 func NewService(
 	client supercell.Client,
 	battleRepo repository.BattleRepository,
 	metaRepo repository.MetaDeckRepository,
+	scheduleRepo repository.PlayerScheduleRepository,
+	statsRepo repository.CollectionStatsRepository,
+	rejectedRepo repository.RejectedBattleRepository,
+	archiveRepo repository.RawBattlelogRepository,
+	historyRepo repository.MetaHistoryRepository,
 	limit int,
+	retentionDays int,
 	logger *log.Logger,
 ) Service {
 	if logger == nil {
 		logger = log.Default()
 	}
+	if retentionDays < 1 {
+		retentionDays = DefaultRetentionDays
+	}
 	return &CollectorService{
 		supercellClient: client,
 		battleRepo:      battleRepo,
 		metaRepo:        metaRepo,
+		scheduleRepo:    scheduleRepo,
+		statsRepo:       statsRepo,
+		rejectedRepo:    rejectedRepo,
+		archiveRepo:     archiveRepo,
+		historyRepo:     historyRepo,
+		batchSize:       defaultBatchSize,
 		limit:           limit,
+		retentionDays:   retentionDays,
 		logger:          logger,
 	}
 }
 
-// Collect performs the full collection process
+// Collect performs the full collection process, resuming an interrupted run if any
 func (c *CollectorService) Collect(ctx context.Context) (*CollectResult, error) {
-	result := &CollectResult{
-		StartedAt: time.Now(),
-		Errors:    make([]error, 0),
-	}
+	return c.CollectWithOptions(ctx, CollectOptions{})
+}
 
+// CollectWithOptions performs the full collection process
+func (c *CollectorService) CollectWithOptions(ctx context.Context, opts CollectOptions) (*CollectResult, error) {
 	// NOTE: Utiliser liste statique car l'endpoint rankings API retourne des listes vides
 	// Bug côté Supercell API identifié le 2026-01-11
 	playerTags := GetTopPlayerTags()
-	playerCount := len(playerTags)
 
-	c.logger.Printf("Starting collection for %d tracked top players", playerCount)
+	c.logger.Printf("Starting collection for %d tracked top players", len(playerTags))
 	c.logger.Println("Note: Using static player list (rankings API unavailable)")
 
+	schedules, err := c.loadSchedules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.collect(ctx, playerTags, schedules, opts)
+}
+
+// CollectDue performs a collection restricted to players whose next fetch is due
+func (c *CollectorService) CollectDue(ctx context.Context) (*CollectResult, error) {
+	if c.scheduleRepo == nil {
+		return c.Collect(ctx)
+	}
+
+	schedules, err := c.loadSchedules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	playerTags := DueTags(GetTopPlayerTags(), schedules, now)
+	if len(playerTags) == 0 {
+		return &CollectResult{
+			StartedAt:   now,
+			CompletedAt: now,
+			Errors:      make([]error, 0),
+			Rejections:  make(map[string]int),
+		}, nil
+	}
+
+	c.logger.Printf("Starting scheduled collection for %d due players", len(playerTags))
+
+	// An interrupted run is resumed with the players due now, those it already
+	// processed being skipped
+	result, err := c.collect(ctx, playerTags, schedules, CollectOptions{})
+	if stderrors.Is(err, ErrCollectionRunning) {
+		c.logger.Printf("Skipping scheduled collection: %v", err)
+		return &CollectResult{
+			StartedAt:   now,
+			CompletedAt: now,
+			Errors:      make([]error, 0),
+			Rejections:  make(map[string]int),
+		}, nil
+	}
+	return result, err
+}
+
+func (c *CollectorService) loadSchedules(ctx context.Context) (map[string]*models.PlayerSchedule, error) {
+	if c.scheduleRepo == nil {
+		return nil, nil
+	}
+
+	schedules, err := c.scheduleRepo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load player schedules: %w", err)
+	}
+	return schedules, nil
+}
+
+// collect fetches, stores and aggregates the battlelogs of playerTags
+func (c *CollectorService) collect(
+	ctx context.Context,
+	playerTags []string,
+	schedules map[string]*models.PlayerSchedule,
+	opts CollectOptions,
+) (*CollectResult, error) {
+	release, err := c.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	run := &collectRun{
+		result: &CollectResult{
+			StartedAt:  time.Now(),
+			Errors:     make([]error, 0),
+			Rejections: make(map[string]int),
+		},
+		pending: make(map[string]playerCounts),
+	}
+
+	stats, playerTags := c.startRun(ctx, run.result, playerTags, opts)
+	run.stats = stats
+
+	result, err := c.runCollection(ctx, run, playerTags, schedules)
+	c.finishRun(ctx, stats, run.result, err)
+	observeRun(ctx, run.result.StartedAt, err)
+
+	return result, err
+}
+
+func (c *CollectorService) runCollection(
+	ctx context.Context,
+	run *collectRun,
+	playerTags []string,
+	schedules map[string]*models.PlayerSchedule,
+) (*CollectResult, error) {
+	result := run.result
+
 	// Convertir []string en []supercell.Player pour compatibilité avec le reste du code
-	players := make([]supercell.Player, playerCount)
+	players := make([]supercell.Player, len(playerTags))
 	for i, tag := range playerTags {
 		players[i] = supercell.Player{
 			Tag: tag,
@@ -68,36 +209,50 @@ func (c *CollectorService) Collect(ctx context.Context) (*CollectResult, error)
 		}
 	}
 
-	c.logger.Printf("Loaded %d player tags from static list", len(players))
+	c.logger.Printf("Loaded %d player tags", len(players))
+
+	if c.scheduleRepo != nil {
+		run.planner = newPollPlanner(schedules)
+	} else {
+		c.noSchedules.Do(func() {
+			c.logger.Println("Warning: player schedules are not stored, battlelog gap detection and adaptive polling are disabled")
+		})
+	}
+
+	run.lastSeen = lastSeenBattles(schedules)
 
-	battles := c.fetchBattlelogsParallel(ctx, players, result)
-	c.logger.Printf("Collected %d raw battles from %d players", len(battles), result.PlayersProcessed)
+	// The daily purge keeps partitions ahead, this covers the first run of a day
+	// following a pause of the collector
+	c.preparePartitions(ctx)
 
-	filtered := FilterPvPLadder(battles)
-	result.BattlesCollected = len(battles)
-	result.BattlesFiltered = len(filtered)
-	c.logger.Printf("Filtered to %d PvP Ladder battles", len(filtered))
+	writer := newBatchWriter(c.battleRepo, c.batchSize, c.checkpoint(run))
+	c.fetchBattlelogsParallel(ctx, players, run, writer)
+	c.logger.Printf("Collected %d raw battles from %d players", result.BattlesCollected, result.PlayersProcessed)
+	c.logger.Printf("Filtered to %d PvP Ladder battles, parsed %d valid battles", result.BattlesFiltered, result.BattlesParsed)
+	for reason, count := range result.Rejections {
+		c.logger.Printf("Rejected %d battles: %s", count, reason)
+	}
 
-	parsed := c.parseBattles(filtered)
-	c.logger.Printf("Parsed %d valid battles", len(parsed))
+	if result.PlayersWithGaps > 0 {
+		c.logger.Printf("Warning: %d/%d players overflowed their battlelog window (coverage %.1f%%)",
+			result.PlayersWithGaps, result.PlayersChecked, result.Coverage())
+	}
 
-	if err := c.battleRepo.BatchInsert(ctx, parsed); err != nil {
-		return nil, fmt.Errorf("failed to insert battles: %w", err)
+	if writer.failed > 0 {
+		c.logger.Printf("Warning: %d battles lost in %d failed batches", writer.failed, writer.failedBatches)
+		if writer.summary == (models.InsertSummary{}) {
+			return nil, fmt.Errorf("failed to insert battles: %w", writer.lastErr)
+		}
 	}
-	result.BattlesStored = len(parsed)
-	c.logger.Printf("Stored %d battles in database", result.BattlesStored)
+	c.logger.Printf("Stored %d new battles in database (%d already known, %d skipped)",
+		result.BattlesStored, result.BattlesDuplicate, result.BattlesSkipped)
 
 	if err := c.metaRepo.Recalculate(ctx); err != nil {
 		return nil, fmt.Errorf("failed to recalculate meta stats: %w", err)
 	}
 	c.logger.Println("Recalculated meta deck statistics")
 
-	deleted, err := c.battleRepo.DeleteOlderThan(ctx, 7)
-	if err != nil {
-		c.logger.Printf("Warning: failed to purge old battles: %v", err)
-	} else {
-		c.logger.Printf("Purged %d old battles (7+ days)", deleted)
-	}
+	c.purge(ctx)
 
 	result.CompletedAt = time.Now()
 	result.Duration = result.CompletedAt.Sub(result.StartedAt)
@@ -107,16 +262,253 @@ func (c *CollectorService) Collect(ctx context.Context) (*CollectResult, error)
 	return result, nil
 }
 
-// fetchBattlelogsParallel fetches battlelogs using a worker pool
+// purge creates the partitions of the coming days and rolls complete days up into
+// the meta history, then deletes battles and archived battlelogs older than the
+// retention period. Battles are kept when the
+// rollup fails so that no day is lost from the history. Both run once a day, on
+// the first collection of the day, and are retried by the next collection on
+// failure.
+func (c *CollectorService) purge(ctx context.Context) {
+	c.preparePartitions(ctx)
+
+	now := time.Now().UTC()
+	if sameDay(c.lastPurge, now) {
+		return
+	}
+
+	if c.historyRepo != nil {
+		rows, err := c.historyRepo.Rollup(ctx, c.retentionDays)
+		if err != nil {
+			c.logger.Printf("Warning: failed to roll up meta history, old battles kept: %v", err)
+			return
+		}
+		c.logger.Printf("Rolled up %d daily deck aggregates into meta history", rows)
+	}
+
+	deleted, err := c.battleRepo.DeleteOlderThan(ctx, c.retentionDays)
+	if err != nil {
+		c.logger.Printf("Warning: failed to purge old battles: %v", err)
+	} else {
+		c.logger.Printf("Purged %d old battles (%d+ days)", deleted, c.retentionDays)
+		c.lastPurge = now
+	}
+
+	if c.archiveRepo != nil {
+		deleted, err := c.archiveRepo.DeleteOlderThan(ctx, c.retentionDays)
+		if err != nil {
+			c.logger.Printf("Warning: failed to purge old archived battlelogs: %v", err)
+		} else {
+			c.logger.Printf("Purged %d old archived battlelogs (%d+ days)", deleted, c.retentionDays)
+		}
+	}
+}
+
+// preparePartitions creates the daily partitions of battles from the retention
+// cutoff to partitionsAhead days ahead, once a day. Battles are inserted without
+// DDL: a battle of a day without partition is skipped.
+func (c *CollectorService) preparePartitions(ctx context.Context) {
+	now := time.Now().UTC()
+	if sameDay(c.lastPartitions, now) {
+		return
+	}
+
+	from := now.AddDate(0, 0, -c.retentionDays)
+	to := now.AddDate(0, 0, partitionsAhead)
+	created, err := c.battleRepo.EnsurePartitions(ctx, from, to)
+	if err != nil {
+		c.logger.Printf("Warning: failed to create battles partitions: %v", err)
+		return
+	}
+	if created > 0 {
+		c.logger.Printf("Created %d daily battles partitions", created)
+	}
+	c.lastPartitions = now
+}
+
+// sameDay reports whether a and b fall on the same UTC calendar day, the day of
+// the battle partitions and of the meta history
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.UTC().Date()
+	by, bm, bd := b.UTC().Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// lock serializes recorded collection runs, so that a run is never resumed or
+// abandoned while another process is still working on it
+func (c *CollectorService) lock(ctx context.Context) (func(), error) {
+	if c.statsRepo == nil {
+		return func() {}, nil
+	}
+
+	release, ok, err := c.statsRepo.TryLock(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock collection: %w", err)
+	}
+	if !ok {
+		return nil, ErrCollectionRunning
+	}
+	return release, nil
+}
+
+// startRun records a running collection, or resumes the interrupted one unless
+// opts.Fresh is set. It returns the run record (nil when runs are not recorded)
+// and the player tags left to process.
+func (c *CollectorService) startRun(
+	ctx context.Context,
+	result *CollectResult,
+	playerTags []string,
+	opts CollectOptions,
+) (*models.CollectionStats, []string) {
+	if c.statsRepo == nil {
+		return nil, playerTags
+	}
+
+	running, err := c.statsRepo.GetRunning(ctx)
+	if err != nil {
+		c.logger.Printf("Warning: failed to look up interrupted collection: %v", err)
+	}
+
+	if running != nil && opts.Fresh {
+		running.Status = models.CollectionAbandoned
+		running.CompletedAt = time.Now()
+		running.ErrorMessage = "abandoned by a fresh collection"
+		if err := c.statsRepo.Update(ctx, running); err != nil {
+			c.logger.Printf("Warning: failed to abandon collection %d: %v", running.ID, err)
+		}
+		running = nil
+	}
+
+	if running != nil {
+		done, err := c.statsRepo.GetCheckpoint(ctx, running.ID)
+		if err == nil {
+			remaining := excludeTags(playerTags, done)
+			c.logger.Printf("Resuming collection %d: %d/%d players already processed",
+				running.ID, len(playerTags)-len(remaining), len(playerTags))
+
+			result.PlayersProcessed = running.PlayersProcessed
+			result.BattlesCollected = running.BattlesCollected
+			result.BattlesStored = running.BattlesStored
+			result.BattlesDuplicate = running.BattlesDuplicate
+			result.BattlesSkipped = running.BattlesSkipped
+			result.PlayersChecked = running.PlayersChecked
+			result.PlayersWithGaps = running.PlayersWithGaps
+			return running, remaining
+		}
+		c.logger.Printf("Warning: failed to load checkpoint of collection %d, starting over: %v", running.ID, err)
+	}
+
+	stats := &models.CollectionStats{StartedAt: result.StartedAt}
+	if err := c.statsRepo.Start(ctx, stats); err != nil {
+		c.logger.Printf("Warning: failed to record collection run: %v", err)
+		return nil, playerTags
+	}
+	return stats, playerTags
+}
+
+// checkpoint returns the commit hook saving processed players, their polling
+// schedules and progress of run. The saved progress only counts the committed
+// players, those still buffered are fetched again when the run is resumed.
+func (c *CollectorService) checkpoint(run *collectRun) commitFunc {
+	return func(ctx context.Context, playerTags []string, summary models.InsertSummary) error {
+		run.result.BattlesStored += summary.Inserted
+		collectorBattlesStored.Add(float64(summary.Inserted))
+		run.result.BattlesDuplicate += summary.Duplicates
+		run.result.BattlesSkipped += summary.Skipped
+		committed := run.commit(playerTags)
+
+		if schedules := run.planner.take(playerTags); len(schedules) > 0 {
+			if err := c.scheduleRepo.Upsert(ctx, schedules); err != nil {
+				c.logger.Printf("Warning: failed to save player schedules: %v", err)
+			}
+		}
+		if run.stats == nil {
+			return nil
+		}
+
+		if err := c.statsRepo.Checkpoint(ctx, run.stats.ID, playerTags); err != nil {
+			return fmt.Errorf("failed to checkpoint collection %d: %w", run.stats.ID, err)
+		}
+
+		run.stats.PlayersProcessed += len(playerTags)
+		run.stats.BattlesCollected += committed.battles
+		run.stats.PlayersChecked += committed.checked
+		run.stats.PlayersWithGaps += committed.gaps
+		run.stats.BattlesStored += summary.Inserted
+		run.stats.BattlesDuplicate += summary.Duplicates
+		run.stats.BattlesSkipped += summary.Skipped
+		run.stats.Errors = len(run.result.Errors)
+		if err := c.statsRepo.Update(ctx, run.stats); err != nil {
+			return fmt.Errorf("failed to save progress of collection %d: %w", run.stats.ID, err)
+		}
+		return nil
+	}
+}
+
+// finishRun stores the outcome of a recorded collection. A run interrupted by
+// context cancellation stays running so that the next collection resumes it.
+func (c *CollectorService) finishRun(ctx context.Context, stats *models.CollectionStats, result *CollectResult, runErr error) {
+	if stats == nil {
+		return
+	}
+
+	if ctx.Err() != nil {
+		c.logger.Printf("Collection %d interrupted, it will be resumed by the next run", stats.ID)
+		return
+	}
+
+	fillStats(stats, result)
+	stats.CompletedAt = time.Now()
+	stats.Status = models.CollectionCompleted
+	if runErr != nil {
+		stats.Status = models.CollectionFailed
+		stats.ErrorMessage = runErr.Error()
+	}
+
+	if err := c.statsRepo.Update(ctx, stats); err != nil {
+		c.logger.Printf("Warning: failed to update collection run %d: %v", stats.ID, err)
+	}
+}
+
+func fillStats(stats *models.CollectionStats, result *CollectResult) {
+	stats.PlayersProcessed = result.PlayersProcessed
+	stats.BattlesCollected = result.BattlesCollected
+	stats.BattlesStored = result.BattlesStored
+	stats.BattlesDuplicate = result.BattlesDuplicate
+	stats.BattlesSkipped = result.BattlesSkipped
+	stats.PlayersChecked = result.PlayersChecked
+	stats.PlayersWithGaps = result.PlayersWithGaps
+	stats.Errors = len(result.Errors)
+}
+
+// excludeTags returns tags not present in done, preserving order
+func excludeTags(tags, done []string) []string {
+	skip := make(map[string]bool, len(done))
+	for _, tag := range done {
+		skip[tag] = true
+	}
+
+	remaining := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if !skip[tag] {
+			remaining = append(remaining, tag)
+		}
+	}
+	return remaining
+}
+
+// fetchBattlelogsParallel fetches battlelogs using a worker pool. Workers filter and
+// parse their battlelog, then stream the battles to writer which commits them in
+// batches, so memory stays bounded and committed batches survive a later failure.
 func (c *CollectorService) fetchBattlelogsParallel(
 	ctx context.Context,
 	players []supercell.Player,
-	result *CollectResult,
-) []supercell.BattleRaw {
+	run *collectRun,
+	writer *batchWriter,
+) {
 	const numWorkers = 10
 
 	jobs := make(chan supercell.Player, len(players))
-	results := make(chan battlelogResult, len(players))
+	results := make(chan battlelogResult, numWorkers)
 
 	var wg sync.WaitGroup
 	for w := 0; w < numWorkers; w++ {
@@ -129,11 +521,18 @@ func (c *CollectorService) fetchBattlelogsParallel(
 					return
 				default:
 					battlelog, err := c.supercellClient.GetBattlelog(ctx, player.Tag)
-					results <- battlelogResult{
+					res := battlelogResult{
 						PlayerTag: player.Tag,
 						Battles:   battlelog,
 						Error:     err,
 					}
+					if err == nil {
+						res.Archive = c.archive(player.Tag, battlelog)
+						filtered := FilterPvPLadder(battlelog)
+						res.Filtered = len(filtered)
+						res.Parsed, res.Rejected = c.parseBattles(player.Tag, filtered)
+					}
+					results <- res
 				}
 			}
 		}()
@@ -149,10 +548,11 @@ func (c *CollectorService) fetchBattlelogsParallel(
 		close(results)
 	}()
 
-	var allBattles []supercell.BattleRaw
+	result := run.result
 	processed := 0
 	for res := range results {
 		processed++
+		result.PlayersProcessed++
 		if processed%100 == 0 {
 			c.logger.Printf("Progress: %d/%d players processed", processed, len(players))
 		}
@@ -160,6 +560,11 @@ func (c *CollectorService) fetchBattlelogsParallel(
 		if res.Error != nil {
 			if apiErr, ok := res.Error.(*errors.APIError); ok {
 				if apiErr.IsNotFound() {
+					// Unknown player: nothing to retry on resume
+					run.pending[res.PlayerTag] = playerCounts{}
+					if err := writer.Add(ctx, res.PlayerTag, nil); err != nil {
+						result.Errors = append(result.Errors, err)
+					}
 					continue
 				}
 			}
@@ -167,31 +572,209 @@ func (c *CollectorService) fetchBattlelogsParallel(
 			continue
 		}
 
-		allBattles = append(allBattles, res.Battles...)
+		run.observe(res)
+		c.storeArchive(ctx, res.Archive)
+		result.BattlesCollected += len(res.Battles)
+		result.BattlesFiltered += res.Filtered
+		result.BattlesParsed += len(res.Parsed)
+		c.recordRejections(ctx, result, res.Rejected)
+
+		if err := writer.Add(ctx, res.PlayerTag, res.Parsed); err != nil {
+			result.Errors = append(result.Errors, err)
+		}
+	}
+
+	if err := writer.Flush(ctx); err != nil {
+		result.Errors = append(result.Errors, err)
+	}
+}
+
+// archive compresses a fetched battlelog when the raw archive is enabled
+func (c *CollectorService) archive(playerTag string, battles []supercell.BattleRaw) *models.RawBattlelog {
+	if c.archiveRepo == nil || len(battles) == 0 {
+		return nil
+	}
+
+	archived, err := archiveBattlelog(playerTag, battles, time.Now().UTC())
+	if err != nil {
+		c.logger.Printf("Warning: %v", err)
+		return nil
+	}
+	return archived
+}
+
+// storeArchive saves an archived battlelog, failures only cost the ability to reparse it
+func (c *CollectorService) storeArchive(ctx context.Context, battlelog *models.RawBattlelog) {
+	if battlelog == nil {
+		return
+	}
+	if err := c.archiveRepo.Insert(ctx, battlelog); err != nil {
+		c.logger.Printf("Warning: failed to archive battlelog of %s: %v", battlelog.PlayerTag, err)
+	}
+}
+
+// recordRejections counts rejected battles per reason and stores them as dead letters
+func (c *CollectorService) recordRejections(ctx context.Context, result *CollectResult, rejected []*models.RejectedBattle) {
+	if len(rejected) == 0 {
+		return
 	}
 
-	result.PlayersProcessed = processed
+	for _, battle := range rejected {
+		result.Rejections[battle.Reason]++
+		collectorRejections.Inc(battle.Reason)
+	}
 
-	return allBattles
+	if c.rejectedRepo == nil {
+		return
+	}
+	if err := c.rejectedRepo.Insert(ctx, rejected); err != nil {
+		c.logger.Printf("Warning: failed to store %d rejected battles: %v", len(rejected), err)
+	}
 }
 
-// parseBattles converts raw battles to internal models
-func (c *CollectorService) parseBattles(battles []supercell.BattleRaw) []*models.Battle {
+// parseBattles converts raw battles of a player to internal models, rejected
+// battles are returned with their reason
+func (c *CollectorService) parseBattles(
+	playerTag string,
+	battles []supercell.BattleRaw,
+) ([]*models.Battle, []*models.RejectedBattle) {
 	parsed := make([]*models.Battle, 0, len(battles))
+	var rejected []*models.RejectedBattle
 	for _, raw := range battles {
 		battle, err := ParseBattle(raw)
 		if err != nil {
+			rejected = append(rejected, newRejectedBattle(playerTag, raw, err))
 			continue
 		}
 		if battle != nil {
 			parsed = append(parsed, battle)
 		}
 	}
-	return parsed
+	return parsed, rejected
+}
+
+func newRejectedBattle(playerTag string, raw supercell.BattleRaw, err error) *models.RejectedBattle {
+	rejected := &models.RejectedBattle{
+		PlayerTag:  playerTag,
+		BattleTime: raw.BattleTime,
+		Reason:     "unknown",
+		Detail:     err.Error(),
+		Raw:        raw.Raw,
+	}
+
+	var parseErr *errors.ParseError
+	if stderrors.As(err, &parseErr) {
+		rejected.Reason = parseErr.Reason
+	}
+
+	if len(rejected.Raw) == 0 {
+		rejected.Raw, _ = json.Marshal(raw)
+	}
+
+	return rejected
+}
+
+// pollPlanner holds the schedule updates of the players fetched during a run
+// until their battles are committed
+type pollPlanner struct {
+	previous map[string]*models.PlayerSchedule
+	pending  map[string]*models.PlayerSchedule
+}
+
+func newPollPlanner(previous map[string]*models.PlayerSchedule) *pollPlanner {
+	return &pollPlanner{
+		previous: previous,
+		pending:  make(map[string]*models.PlayerSchedule),
+	}
+}
+
+// observe records a fetched battlelog, it is a no-op on a nil planner
+func (p *pollPlanner) observe(tag string, battles []supercell.BattleRaw) {
+	if p == nil {
+		return
+	}
+	p.pending[tag] = UpdateSchedule(p.previous[tag], tag, battles, time.Now())
+}
+
+// take returns and forgets the schedule updates of playerTags, whose battles
+// are committed. It returns nil on a nil planner.
+func (p *pollPlanner) take(playerTags []string) []*models.PlayerSchedule {
+	if p == nil {
+		return nil
+	}
+	var schedules []*models.PlayerSchedule
+	for _, tag := range playerTags {
+		if schedule, ok := p.pending[tag]; ok {
+			schedules = append(schedules, schedule)
+			delete(p.pending, tag)
+		}
+	}
+	return schedules
+}
+
+// collectRun holds the state of a single collection run
+type collectRun struct {
+	result   *CollectResult
+	stats    *models.CollectionStats
+	planner  *pollPlanner
+	lastSeen map[string]time.Time    // player tag -> latest battle seen at the previous fetch
+	pending  map[string]playerCounts // player tag -> counters until its battles are committed
+}
+
+// playerCounts holds the counters of a processed player, or the sum over players
+type playerCounts struct {
+	battles int
+	checked int
+	gaps    int
+}
+
+// commit returns the summed counters of playerTags, whose battles are committed
+func (r *collectRun) commit(playerTags []string) playerCounts {
+	var sum playerCounts
+	for _, tag := range playerTags {
+		counts := r.pending[tag]
+		delete(r.pending, tag)
+		sum.battles += counts.battles
+		sum.checked += counts.checked
+		sum.gaps += counts.gaps
+	}
+	return sum
+}
+
+// lastSeenBattles returns the latest battle time, of any mode, seen from the API
+// for every player fetched before
+func lastSeenBattles(schedules map[string]*models.PlayerSchedule) map[string]time.Time {
+	lastSeen := make(map[string]time.Time, len(schedules))
+	for tag, schedule := range schedules {
+		if !schedule.LastBattleTime.IsZero() {
+			lastSeen[tag] = schedule.LastBattleTime
+		}
+	}
+	return lastSeen
+}
+
+// observe updates gap detection and polling state with a fetched battlelog
+func (r *collectRun) observe(res battlelogResult) {
+	counts := playerCounts{battles: len(res.Battles)}
+	if lastSeen, ok := r.lastSeen[res.PlayerTag]; ok {
+		counts.checked = 1
+		if DetectGap(lastSeen, res.Battles) {
+			counts.gaps = 1
+		}
+	}
+	r.pending[res.PlayerTag] = counts
+
+	r.result.PlayersChecked += counts.checked
+	r.result.PlayersWithGaps += counts.gaps
+	r.planner.observe(res.PlayerTag, res.Battles)
 }
 
 type battlelogResult struct {
 	PlayerTag string
 	Battles   []supercell.BattleRaw
+	Filtered  int
+	Parsed    []*models.Battle
+	Rejected  []*models.RejectedBattle
+	Archive   *models.RawBattlelog
 	Error     error
 }
@@ -0,0 +1,556 @@
+package collector
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leopoldhub/royal-api-personal/internal/models"
+	"github.com/leopoldhub/royal-api-personal/pkg/supercell"
+)
+
+func newFixtureService(battleRepo *memoryBattleRepo, metaRepo *memoryMetaRepo) *CollectorService {
+	client := supercell.NewFixtureClient("testdata/fixtures")
+	logger := log.New(io.Discard, "", 0)
+	return NewService(client, battleRepo, metaRepo, nil, nil, nil, nil, nil, 0, 0, logger).(*CollectorService)
+}
+
+func TestCollect_Fixtures(t *testing.T) {
+	battleRepo := &memoryBattleRepo{}
+	metaRepo := &memoryMetaRepo{}
+	service := newFixtureService(battleRepo, metaRepo)
+
+	result, err := service.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	if result.PlayersProcessed != GetTopPlayerCount() {
+		t.Errorf("PlayersProcessed = %d, want %d", result.PlayersProcessed, GetTopPlayerCount())
+	}
+	if len(result.Errors) != 0 {
+		t.Errorf("expected missing fixtures to be skipped as not found, got %v", result.Errors)
+	}
+	if result.BattlesCollected != 7 {
+		t.Errorf("BattlesCollected = %d, want 7", result.BattlesCollected)
+	}
+	if result.BattlesFiltered != 6 {
+		t.Errorf("BattlesFiltered = %d, want 6", result.BattlesFiltered)
+	}
+	if result.BattlesStored != 5 || len(battleRepo.battles) != 5 {
+		t.Errorf("BattlesStored = %d (repo %d), want 5", result.BattlesStored, len(battleRepo.battles))
+	}
+	if result.Rejections[RejectCardCount] != 1 || len(result.Rejections) != 1 {
+		t.Errorf("Rejections = %v, want 1 %s", result.Rejections, RejectCardCount)
+	}
+	if metaRepo.recalculated != 1 {
+		t.Errorf("expected meta stats to be recalculated once, got %d", metaRepo.recalculated)
+	}
+}
+
+func TestCollect_FailedBatchKeepsPartialProgress(t *testing.T) {
+	battleRepo := &memoryBattleRepo{failOnCall: 2}
+	metaRepo := &memoryMetaRepo{}
+	service := newFixtureService(battleRepo, metaRepo)
+	service.batchSize = 2
+
+	result, err := service.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	if result.BattlesStored != 3 || len(battleRepo.battles) != 3 {
+		t.Errorf("BattlesStored = %d (repo %d), want 3 committed battles", result.BattlesStored, len(battleRepo.battles))
+	}
+	if len(result.Errors) != 1 {
+		t.Errorf("expected the failed batch to be reported, got %v", result.Errors)
+	}
+	if metaRepo.recalculated != 1 {
+		t.Errorf("expected meta stats to be recalculated after partial insert, got %d", metaRepo.recalculated)
+	}
+}
+
+func TestCollect_AllBatchesFailed(t *testing.T) {
+	battleRepo := &memoryBattleRepo{failOnCall: 1}
+	service := newFixtureService(battleRepo, &memoryMetaRepo{})
+
+	if _, err := service.Collect(context.Background()); err == nil {
+		t.Fatal("expected an error when no batch could be stored")
+	}
+}
+
+func TestCollect_DetectsGapsOnSecondRun(t *testing.T) {
+	battleRepo := &memoryBattleRepo{}
+	service := newFixtureService(battleRepo, &memoryMetaRepo{})
+	service.scheduleRepo = &memoryScheduleRepo{}
+
+	first, err := service.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if first.PlayersChecked != 0 {
+		t.Errorf("first run PlayersChecked = %d, want 0", first.PlayersChecked)
+	}
+
+	second, err := service.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if second.PlayersChecked != 2 || second.PlayersWithGaps != 0 {
+		t.Errorf("second run checked/gaps = %d/%d, want 2/0", second.PlayersChecked, second.PlayersWithGaps)
+	}
+	if second.BattlesStored != 0 || second.BattlesDuplicate != 5 {
+		t.Errorf("second run stored/duplicate = %d/%d, want 0/5", second.BattlesStored, second.BattlesDuplicate)
+	}
+}
+
+func TestCollect_DetectsGapsAgainstLastSeenBattle(t *testing.T) {
+	service := newFixtureService(&memoryBattleRepo{}, &memoryMetaRepo{})
+	service.scheduleRepo = &memoryScheduleRepo{schedules: map[string]*models.PlayerSchedule{
+		// The 2PP battlelog starts at 09:00, battles played after 08:30 were lost
+		"#2PP": {PlayerTag: "#2PP", LastBattleTime: time.Date(2026, 1, 15, 8, 30, 0, 0, time.UTC)},
+		// The PQVLP028C battlelog still holds the battle seen at the previous fetch
+		"#PQVLP028C": {PlayerTag: "#PQVLP028C", LastBattleTime: time.Date(2026, 1, 15, 7, 0, 0, 0, time.UTC)},
+	}}
+
+	result, err := service.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if result.PlayersChecked != 2 || result.PlayersWithGaps != 1 {
+		t.Errorf("checked/gaps = %d/%d, want 2/1", result.PlayersChecked, result.PlayersWithGaps)
+	}
+}
+
+func interruptedRun(t *testing.T, statsRepo *memoryStatsRepo) {
+	t.Helper()
+
+	// Every tracked player but #PQVLP028C was processed before the interruption
+	var done []string
+	for _, tag := range GetTopPlayerTags() {
+		if tag != "#PQVLP028C" {
+			done = append(done, tag)
+		}
+	}
+
+	ctx := context.Background()
+	stats := &models.CollectionStats{StartedAt: time.Now().Add(-time.Hour)}
+	if err := statsRepo.Start(ctx, stats); err != nil {
+		t.Fatal(err)
+	}
+	stats.PlayersProcessed = len(done)
+	stats.BattlesStored = 3
+	if err := statsRepo.Update(ctx, stats); err != nil {
+		t.Fatal(err)
+	}
+	if err := statsRepo.Checkpoint(ctx, stats.ID, done); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCollect_WarnsOnceWithoutSchedules(t *testing.T) {
+	service := newFixtureService(&memoryBattleRepo{}, &memoryMetaRepo{})
+	var logs strings.Builder
+	service.logger = log.New(&logs, "", 0)
+
+	for i := 0; i < 2; i++ {
+		if _, err := service.Collect(context.Background()); err != nil {
+			t.Fatalf("Collect() error = %v", err)
+		}
+	}
+
+	if got := strings.Count(logs.String(), "gap detection and adaptive polling are disabled"); got != 1 {
+		t.Errorf("disabled gap detection logged %d times, want once", got)
+	}
+}
+
+func TestCollect_ResumesInterruptedRun(t *testing.T) {
+	battleRepo := &memoryBattleRepo{}
+	statsRepo := &memoryStatsRepo{}
+	interruptedRun(t, statsRepo)
+
+	service := newFixtureService(battleRepo, &memoryMetaRepo{})
+	service.statsRepo = statsRepo
+
+	result, err := service.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	if len(battleRepo.battles) != 2 {
+		t.Errorf("expected only the remaining player to be fetched, stored %d battles", len(battleRepo.battles))
+	}
+	if result.PlayersProcessed != GetTopPlayerCount() {
+		t.Errorf("PlayersProcessed = %d, want %d", result.PlayersProcessed, GetTopPlayerCount())
+	}
+	if result.BattlesStored != 5 {
+		t.Errorf("BattlesStored = %d, want 3 resumed + 2 new", result.BattlesStored)
+	}
+	if len(statsRepo.runs) != 1 || statsRepo.runs[0].Status != models.CollectionCompleted {
+		t.Errorf("expected the interrupted run to be completed, got %+v", statsRepo.runs)
+	}
+}
+
+func TestCollect_FreshAbandonsInterruptedRun(t *testing.T) {
+	battleRepo := &memoryBattleRepo{}
+	statsRepo := &memoryStatsRepo{}
+	interruptedRun(t, statsRepo)
+
+	service := newFixtureService(battleRepo, &memoryMetaRepo{})
+	service.statsRepo = statsRepo
+
+	if _, err := service.CollectWithOptions(context.Background(), CollectOptions{Fresh: true}); err != nil {
+		t.Fatalf("CollectWithOptions() error = %v", err)
+	}
+
+	if len(battleRepo.battles) != 5 {
+		t.Errorf("expected every player to be fetched, stored %d battles", len(battleRepo.battles))
+	}
+	if len(statsRepo.runs) != 2 {
+		t.Fatalf("expected a new run to be recorded, got %d runs", len(statsRepo.runs))
+	}
+	if statsRepo.runs[0].Status != models.CollectionAbandoned {
+		t.Errorf("interrupted run status = %s, want %s", statsRepo.runs[0].Status, models.CollectionAbandoned)
+	}
+	if statsRepo.runs[1].Status != models.CollectionCompleted {
+		t.Errorf("fresh run status = %s, want %s", statsRepo.runs[1].Status, models.CollectionCompleted)
+	}
+	if got := len(statsRepo.checkpoints[2]); got != GetTopPlayerCount() {
+		t.Errorf("fresh run checkpointed %d players, want %d", got, GetTopPlayerCount())
+	}
+}
+
+func TestCollect_ResumesRunInterruptedBetweenFlushes(t *testing.T) {
+	battleRepo := &memoryBattleRepo{}
+	statsRepo := &memoryStatsRepo{}
+	service := newFixtureService(battleRepo, &memoryMetaRepo{})
+	service.statsRepo = statsRepo
+	service.batchSize = 1
+
+	// The run is killed right after its first batch: the player whose battle
+	// triggered the flush is processed but not committed yet
+	ctx, cancel := context.WithCancel(context.Background())
+	battleRepo.onInsert = cancel
+	if _, err := service.Collect(ctx); err != nil {
+		t.Fatalf("interrupted Collect() error = %v", err)
+	}
+	if statsRepo.runs[0].Status != models.CollectionRunning {
+		t.Fatalf("interrupted run status = %s, want %s", statsRepo.runs[0].Status, models.CollectionRunning)
+	}
+	if got, done := statsRepo.runs[0].PlayersProcessed, len(statsRepo.checkpoints[1]); got != done {
+		t.Errorf("interrupted run PlayersProcessed = %d, want the %d checkpointed players", got, done)
+	}
+
+	battleRepo.onInsert = nil
+	result, err := service.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("resumed Collect() error = %v", err)
+	}
+
+	if result.PlayersProcessed != GetTopPlayerCount() {
+		t.Errorf("PlayersProcessed = %d, want %d", result.PlayersProcessed, GetTopPlayerCount())
+	}
+	if result.BattlesCollected != 7 {
+		t.Errorf("BattlesCollected = %d, want 7", result.BattlesCollected)
+	}
+	if result.BattlesStored != 5 || len(battleRepo.battles) != 5 {
+		t.Errorf("BattlesStored = %d (repo %d), want 5", result.BattlesStored, len(battleRepo.battles))
+	}
+	if len(statsRepo.runs) != 1 || statsRepo.runs[0].Status != models.CollectionCompleted {
+		t.Errorf("expected the interrupted run to be completed, got %+v", statsRepo.runs)
+	}
+}
+
+func TestCollect_SavesSchedulesOfCommittedPlayers(t *testing.T) {
+	// The second battle insert fails: the player it belongs to is fetched again
+	// on resume, its schedule must not postpone that fetch
+	battleRepo := &memoryBattleRepo{failOnCall: 2}
+	statsRepo := &memoryStatsRepo{}
+	scheduleRepo := &memoryScheduleRepo{}
+	service := newFixtureService(battleRepo, &memoryMetaRepo{})
+	service.statsRepo = statsRepo
+	service.scheduleRepo = scheduleRepo
+	service.batchSize = 1
+
+	if _, err := service.Collect(context.Background()); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	done := make(map[string]bool)
+	for _, tag := range statsRepo.checkpoints[1] {
+		done[tag] = true
+	}
+	if len(scheduleRepo.schedules) != 1 {
+		t.Errorf("saved %d schedules, want the committed player's only", len(scheduleRepo.schedules))
+	}
+	for tag := range scheduleRepo.schedules {
+		if !done[tag] {
+			t.Errorf("schedule of uncommitted player %s saved", tag)
+		}
+	}
+}
+
+func TestCollectDue_ResumesInterruptedRun(t *testing.T) {
+	battleRepo := &memoryBattleRepo{}
+	statsRepo := &memoryStatsRepo{}
+	interruptedRun(t, statsRepo)
+
+	service := newFixtureService(battleRepo, &memoryMetaRepo{})
+	service.statsRepo = statsRepo
+	service.scheduleRepo = &memoryScheduleRepo{}
+
+	if _, err := service.CollectDue(context.Background()); err != nil {
+		t.Fatalf("CollectDue() error = %v", err)
+	}
+
+	if len(battleRepo.battles) != 2 {
+		t.Errorf("expected only the remaining player to be fetched, stored %d battles", len(battleRepo.battles))
+	}
+	if len(statsRepo.runs) != 1 || statsRepo.runs[0].Status != models.CollectionCompleted {
+		t.Errorf("expected the interrupted run to be resumed, got %+v", statsRepo.runs)
+	}
+}
+
+func TestCollect_LockedByAnotherProcess(t *testing.T) {
+	battleRepo := &memoryBattleRepo{}
+	statsRepo := &memoryStatsRepo{locked: true}
+	interruptedRun(t, statsRepo)
+
+	service := newFixtureService(battleRepo, &memoryMetaRepo{})
+	service.statsRepo = statsRepo
+	service.scheduleRepo = &memoryScheduleRepo{}
+
+	if _, err := service.CollectWithOptions(context.Background(), CollectOptions{Fresh: true}); !stderrors.Is(err, ErrCollectionRunning) {
+		t.Errorf("CollectWithOptions() error = %v, want %v", err, ErrCollectionRunning)
+	}
+
+	result, err := service.CollectDue(context.Background())
+	if err != nil {
+		t.Fatalf("CollectDue() error = %v, want the tick to be skipped", err)
+	}
+	if result.PlayersProcessed != 0 || len(battleRepo.battles) != 0 {
+		t.Errorf("expected no player to be fetched, processed %d", result.PlayersProcessed)
+	}
+	if len(statsRepo.runs) != 1 || statsRepo.runs[0].Status != models.CollectionRunning {
+		t.Errorf("expected the running collection to be left alone, got %+v", statsRepo.runs)
+	}
+}
+
+func TestCollect_StoresRejectedBattles(t *testing.T) {
+	rejectedRepo := &memoryRejectedRepo{}
+	service := newFixtureService(&memoryBattleRepo{}, &memoryMetaRepo{})
+	service.rejectedRepo = rejectedRepo
+
+	if _, err := service.Collect(context.Background()); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	if len(rejectedRepo.rejected) != 1 {
+		t.Fatalf("expected 1 dead letter, got %d", len(rejectedRepo.rejected))
+	}
+
+	rejected := rejectedRepo.rejected[0]
+	if rejected.PlayerTag != "#PQVLP028C" || rejected.Reason != RejectCardCount {
+		t.Errorf("unexpected dead letter: %+v", rejected)
+	}
+	// The raw payload is kept as received, including fields the models ignore
+	if !strings.Contains(string(rejected.Raw), `"maxLevel"`) {
+		t.Errorf("expected original payload to be stored, got %s", rejected.Raw)
+	}
+}
+
+func TestReparse_RebuildsBattlesFromArchive(t *testing.T) {
+	battleRepo := &memoryBattleRepo{}
+	metaRepo := &memoryMetaRepo{}
+	archiveRepo := &memoryArchiveRepo{}
+	service := newFixtureService(battleRepo, metaRepo)
+	service.archiveRepo = archiveRepo
+
+	if _, err := service.Collect(context.Background()); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if len(archiveRepo.battlelogs) != 2 {
+		t.Fatalf("expected 2 archived battlelogs, got %d", len(archiveRepo.battlelogs))
+	}
+
+	// A battle stored by an older parser within the span of the 2PP battlelog
+	stale := &models.Battle{
+		PlayerTag:  "#2PP",
+		BattleTime: time.Date(2026, 1, 15, 10, 30, 0, 0, time.UTC),
+	}
+	battleRepo.battles = append(battleRepo.battles[:0], stale)
+
+	result, err := service.Reparse(context.Background())
+	if err != nil {
+		t.Fatalf("Reparse() error = %v", err)
+	}
+
+	if result.Battlelogs != 2 || result.BattlesParsed != 5 {
+		t.Errorf("battlelogs/parsed = %d/%d, want 2/5", result.Battlelogs, result.BattlesParsed)
+	}
+	if result.BattlesDeleted != 1 || result.BattlesStored != 5 {
+		t.Errorf("deleted/stored = %d/%d, want 1/5", result.BattlesDeleted, result.BattlesStored)
+	}
+	if len(battleRepo.battles) != 5 {
+		t.Errorf("expected the stale battle to be replaced, repo holds %d battles", len(battleRepo.battles))
+	}
+	if result.Rejections[RejectCardCount] != 1 {
+		t.Errorf("Rejections = %v, want 1 %s", result.Rejections, RejectCardCount)
+	}
+	if metaRepo.recalculated != 2 {
+		t.Errorf("expected meta stats to be recalculated after reparse, got %d", metaRepo.recalculated)
+	}
+}
+
+func TestReparse_KeepsBattlesWhenReplaceFails(t *testing.T) {
+	battleRepo := &memoryBattleRepo{}
+	archiveRepo := &memoryArchiveRepo{}
+	historyRepo := &memoryHistoryRepo{}
+	service := newFixtureService(battleRepo, &memoryMetaRepo{})
+	service.archiveRepo = archiveRepo
+	service.historyRepo = historyRepo
+
+	if _, err := service.Collect(context.Background()); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	// The replacement of the first battlelog fails
+	battleRepo.failOnCall = battleRepo.calls + 1
+	result, err := service.Reparse(context.Background())
+	if err != nil {
+		t.Fatalf("Reparse() error = %v", err)
+	}
+
+	if len(result.Errors) != 1 {
+		t.Errorf("expected the failed replacement to be reported, got %v", result.Errors)
+	}
+	if len(battleRepo.battles) != 5 {
+		t.Errorf("expected the battles of the failed battlelog to be kept, repo holds %d battles", len(battleRepo.battles))
+	}
+	if len(historyRepo.rebuilds) != 1 {
+		t.Fatalf("expected the meta history to be rebuilt once, got %d", len(historyRepo.rebuilds))
+	}
+	if from, to := historyRepo.rebuilds[0][0], historyRepo.rebuilds[0][1]; to.Before(from) {
+		t.Errorf("rebuilt range %v - %v is reversed", from, to)
+	}
+}
+
+func TestReparse_RequiresArchive(t *testing.T) {
+	service := newFixtureService(&memoryBattleRepo{}, &memoryMetaRepo{})
+
+	if _, err := service.Reparse(context.Background()); err == nil {
+		t.Error("expected an error when the archive is disabled")
+	}
+}
+
+func TestCollect_RollsUpHistoryBeforePurge(t *testing.T) {
+	battleRepo := &memoryBattleRepo{}
+	historyRepo := &memoryHistoryRepo{}
+	service := newFixtureService(battleRepo, &memoryMetaRepo{})
+	service.historyRepo = historyRepo
+	service.retentionDays = 30
+
+	if _, err := service.Collect(context.Background()); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	if len(historyRepo.rollups) != 1 || historyRepo.rollups[0] != 30 {
+		t.Errorf("rollups = %v, want one rollup over 30 days", historyRepo.rollups)
+	}
+	if len(battleRepo.purgedDays) != 1 || battleRepo.purgedDays[0] != 30 {
+		t.Errorf("purges = %v, want one purge of 30+ days", battleRepo.purgedDays)
+	}
+}
+
+func TestCollect_PreparesPartitionsOncePerDay(t *testing.T) {
+	battleRepo := &memoryBattleRepo{}
+	service := newFixtureService(battleRepo, &memoryMetaRepo{})
+	service.retentionDays = 30
+
+	for i := 0; i < 2; i++ {
+		if _, err := service.Collect(context.Background()); err != nil {
+			t.Fatalf("Collect() error = %v", err)
+		}
+	}
+
+	if len(battleRepo.partitions) != 1 {
+		t.Fatalf("partitions = %v, want a single creation", battleRepo.partitions)
+	}
+	from, to := battleRepo.partitions[0][0], battleRepo.partitions[0][1]
+	if days := to.Sub(from).Hours() / 24; days != 30+partitionsAhead {
+		t.Errorf("partitions cover %.0f days, want %d", days, 30+partitionsAhead)
+	}
+	if !to.After(time.Now().AddDate(0, 0, partitionsAhead-1)) {
+		t.Errorf("partitions end at %v, want %d days ahead", to, partitionsAhead)
+	}
+}
+
+func TestCollect_KeepsBattlesWhenRollupFails(t *testing.T) {
+	battleRepo := &memoryBattleRepo{}
+	service := newFixtureService(battleRepo, &memoryMetaRepo{})
+	service.historyRepo = &memoryHistoryRepo{err: fmt.Errorf("rollup failed")}
+
+	if _, err := service.Collect(context.Background()); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	if len(battleRepo.purgedDays) != 0 {
+		t.Errorf("expected no purge after a failed rollup, got %v", battleRepo.purgedDays)
+	}
+}
+
+func TestCollect_PurgesOncePerDay(t *testing.T) {
+	battleRepo := &memoryBattleRepo{}
+	historyRepo := &memoryHistoryRepo{err: fmt.Errorf("rollup failed")}
+	service := newFixtureService(battleRepo, &memoryMetaRepo{})
+	service.historyRepo = historyRepo
+
+	// A failed rollup is retried by the next collection, then both are done for the day
+	for i := 0; i < 3; i++ {
+		if i == 1 {
+			historyRepo.err = nil
+		}
+		if _, err := service.Collect(context.Background()); err != nil {
+			t.Fatalf("Collect() error = %v", err)
+		}
+	}
+
+	if len(historyRepo.rollups) != 1 || len(battleRepo.purgedDays) != 1 {
+		t.Errorf("rollups/purges = %v/%v, want a single rollup and purge", historyRepo.rollups, battleRepo.purgedDays)
+	}
+
+	service.lastPurge = service.lastPurge.AddDate(0, 0, -1)
+	if _, err := service.Collect(context.Background()); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if len(battleRepo.purgedDays) != 2 {
+		t.Errorf("purges = %v, want a new purge on the next day", battleRepo.purgedDays)
+	}
+}
+
+func TestSameDay_UsesUTC(t *testing.T) {
+	paris := time.FixedZone("CEST", 2*60*60)
+	// 23:30 UTC is already the next day in Paris
+	late := time.Date(2026, 6, 1, 23, 30, 0, 0, time.UTC)
+	early := time.Date(2026, 6, 1, 3, 0, 0, 0, paris)
+
+	if !sameDay(late.In(paris), early) {
+		t.Error("sameDay() = false for times of the same UTC day")
+	}
+	if sameDay(late, late.Add(time.Hour).In(paris)) {
+		t.Error("sameDay() = true across UTC midnight")
+	}
+}
+
+func TestNewService_DefaultRetention(t *testing.T) {
+	service := newFixtureService(&memoryBattleRepo{}, &memoryMetaRepo{})
+
+	if service.retentionDays != DefaultRetentionDays {
+		t.Errorf("retentionDays = %d, want %d", service.retentionDays, DefaultRetentionDays)
+	}
+}
@@ -0,0 +1,368 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/leopoldhub/royal-api-personal/internal/models"
+)
+
+// memoryBattleRepo is an in-memory BattleRepository for collector tests
+type memoryBattleRepo struct {
+	mu         sync.Mutex
+	battles    []*models.Battle
+	calls      int
+	failOnCall int    // BatchInsert call (1-based) returning an error, 0 to never fail
+	onInsert   func() // called after every committed batch
+	purgedDays []int
+	partitions [][2]time.Time // EnsurePartitions ranges
+}
+
+func (r *memoryBattleRepo) Insert(ctx context.Context, battle *models.Battle) error {
+	_, err := r.BatchInsert(ctx, []*models.Battle{battle})
+	return err
+}
+
+func (r *memoryBattleRepo) BatchInsert(ctx context.Context, battles []*models.Battle) (models.InsertSummary, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var summary models.InsertSummary
+	r.calls++
+	if r.calls == r.failOnCall {
+		return summary, fmt.Errorf("insert failed on call %d", r.calls)
+	}
+	if err := ctx.Err(); err != nil {
+		return summary, err
+	}
+	if r.onInsert != nil {
+		defer r.onInsert()
+	}
+
+	for _, battle := range battles {
+		if r.contains(battle) {
+			summary.Duplicates++
+			continue
+		}
+		r.battles = append(r.battles, battle)
+		summary.Inserted++
+	}
+	return summary, nil
+}
+
+func (r *memoryBattleRepo) contains(battle *models.Battle) bool {
+	for _, stored := range r.battles {
+		if stored.PlayerTag == battle.PlayerTag && stored.BattleTime.Equal(battle.BattleTime) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *memoryBattleRepo) EnsurePartitions(ctx context.Context, from, to time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.partitions = append(r.partitions, [2]time.Time{from, to})
+	return 0, nil
+}
+
+func (r *memoryBattleRepo) DeleteOlderThan(ctx context.Context, days int) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.purgedDays = append(r.purgedDays, days)
+	return 0, nil
+}
+
+func (r *memoryBattleRepo) ReplacePlayerRange(
+	ctx context.Context,
+	playerTag string,
+	from, to time.Time,
+	battles []*models.Battle,
+) (int64, models.InsertSummary, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var summary models.InsertSummary
+	r.calls++
+	if r.calls == r.failOnCall {
+		return 0, summary, fmt.Errorf("replace failed on call %d", r.calls)
+	}
+
+	kept := make([]*models.Battle, 0, len(r.battles))
+	var deleted int64
+	for _, battle := range r.battles {
+		if battle.PlayerTag == playerTag && !battle.BattleTime.Before(from) && !battle.BattleTime.After(to) {
+			deleted++
+			continue
+		}
+		kept = append(kept, battle)
+	}
+	r.battles = kept
+
+	for _, battle := range battles {
+		if r.contains(battle) {
+			summary.Duplicates++
+			continue
+		}
+		r.battles = append(r.battles, battle)
+		summary.Inserted++
+	}
+	return deleted, summary, nil
+}
+
+func (r *memoryBattleRepo) Count(ctx context.Context) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.battles), nil
+}
+
+func (r *memoryBattleRepo) GetRecent(ctx context.Context, deckSignature string, limit int) ([]*models.Battle, error) {
+	return nil, nil
+}
+
+// memoryMetaRepo is a MetaDeckRepository counting recalculations
+type memoryMetaRepo struct {
+	recalculated int
+}
+
+func (r *memoryMetaRepo) Recalculate(ctx context.Context) error {
+	r.recalculated++
+	return nil
+}
+
+func (r *memoryMetaRepo) GetTop(ctx context.Context, page models.Page, sortBy string, minGames int) ([]*models.Deck, int, error) {
+	return nil, 0, nil
+}
+
+func (r *memoryMetaRepo) GetTopByPatch(ctx context.Context, patchID int, page models.Page, sortBy string, minGames int) ([]*models.Deck, int, error) {
+	return nil, 0, nil
+}
+
+func (r *memoryMetaRepo) GetBySignature(ctx context.Context, signature string) (*models.Deck, error) {
+	return nil, nil
+}
+
+func (r *memoryMetaRepo) Count(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+func (r *memoryMetaRepo) LastUpdated(ctx context.Context) (time.Time, error) {
+	return time.Time{}, nil
+}
+
+func (r *memoryMetaRepo) GetWindowStats(ctx context.Context, baselineFrom, recentFrom time.Time, minRecentGames int) ([]*models.DeckWindowStats, error) {
+	return nil, nil
+}
+
+// memoryStatsRepo is an in-memory CollectionStatsRepository
+type memoryStatsRepo struct {
+	mu          sync.Mutex
+	runs        []*models.CollectionStats
+	checkpoints map[int][]string
+	locked      bool
+}
+
+func (r *memoryStatsRepo) Start(ctx context.Context, stats *models.CollectionStats) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats.ID = len(r.runs) + 1
+	stats.Status = models.CollectionRunning
+	copied := *stats
+	r.runs = append(r.runs, &copied)
+	return nil
+}
+
+func (r *memoryStatsRepo) Update(ctx context.Context, stats *models.CollectionStats) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	copied := *stats
+	r.runs[stats.ID-1] = &copied
+	return nil
+}
+
+func (r *memoryStatsRepo) GetLatest(ctx context.Context) (*models.CollectionStats, error) {
+	runs, _, _ := r.GetRecent(ctx, models.Page{Limit: 1})
+	if len(runs) == 0 {
+		return nil, nil
+	}
+	return runs[0], nil
+}
+
+func (r *memoryStatsRepo) GetRecent(ctx context.Context, page models.Page) ([]*models.CollectionStats, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var completed []*models.CollectionStats
+	for i := len(r.runs) - 1; i >= 0; i-- {
+		if r.runs[i].Status == models.CollectionCompleted {
+			copied := *r.runs[i]
+			completed = append(completed, &copied)
+		}
+	}
+
+	runs := completed[min(page.Offset, len(completed)):min(page.Offset+page.Limit, len(completed))]
+	return runs, len(completed), nil
+}
+
+func (r *memoryStatsRepo) GetRunning(ctx context.Context) (*models.CollectionStats, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := len(r.runs) - 1; i >= 0; i-- {
+		if r.runs[i].Status == models.CollectionRunning {
+			copied := *r.runs[i]
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryStatsRepo) Checkpoint(ctx context.Context, collectionID int, playerTags []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.checkpoints == nil {
+		r.checkpoints = make(map[int][]string)
+	}
+	r.checkpoints[collectionID] = append(r.checkpoints[collectionID], playerTags...)
+	return nil
+}
+
+func (r *memoryStatsRepo) GetCheckpoint(ctx context.Context, collectionID int) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.checkpoints[collectionID]...), nil
+}
+
+func (r *memoryStatsRepo) TryLock(ctx context.Context) (func(), bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.locked {
+		return nil, false, nil
+	}
+	r.locked = true
+	release := func() {
+		r.mu.Lock()
+		r.locked = false
+		r.mu.Unlock()
+	}
+	return release, true, nil
+}
+
+// memoryScheduleRepo is an in-memory PlayerScheduleRepository
+type memoryScheduleRepo struct {
+	mu        sync.Mutex
+	schedules map[string]*models.PlayerSchedule
+}
+
+func (r *memoryScheduleRepo) GetAll(ctx context.Context) (map[string]*models.PlayerSchedule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	schedules := make(map[string]*models.PlayerSchedule, len(r.schedules))
+	for tag, schedule := range r.schedules {
+		copied := *schedule
+		schedules[tag] = &copied
+	}
+	return schedules, nil
+}
+
+func (r *memoryScheduleRepo) Upsert(ctx context.Context, schedules []*models.PlayerSchedule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.schedules == nil {
+		r.schedules = make(map[string]*models.PlayerSchedule)
+	}
+	for _, schedule := range schedules {
+		r.schedules[schedule.PlayerTag] = schedule
+	}
+	return nil
+}
+
+// memoryRejectedRepo is an in-memory RejectedBattleRepository
+type memoryRejectedRepo struct {
+	mu       sync.Mutex
+	rejected []*models.RejectedBattle
+}
+
+func (r *memoryRejectedRepo) Insert(ctx context.Context, rejected []*models.RejectedBattle) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rejected = append(r.rejected, rejected...)
+	return nil
+}
+
+func (r *memoryRejectedRepo) CountByReason(ctx context.Context, days int) (map[string]int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	counts := make(map[string]int)
+	for _, battle := range r.rejected {
+		counts[battle.Reason]++
+	}
+	return counts, nil
+}
+
+// memoryArchiveRepo is an in-memory RawBattlelogRepository
+type memoryArchiveRepo struct {
+	mu         sync.Mutex
+	battlelogs []*models.RawBattlelog
+}
+
+func (r *memoryArchiveRepo) Insert(ctx context.Context, battlelog *models.RawBattlelog) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	battlelog.ID = int64(len(r.battlelogs) + 1)
+	r.battlelogs = append(r.battlelogs, battlelog)
+	return nil
+}
+
+func (r *memoryArchiveRepo) List(ctx context.Context, afterID int64, limit int) ([]*models.RawBattlelog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var page []*models.RawBattlelog
+	for _, battlelog := range r.battlelogs {
+		if battlelog.ID > afterID && len(page) < limit {
+			page = append(page, battlelog)
+		}
+	}
+	return page, nil
+}
+
+func (r *memoryArchiveRepo) DeleteOlderThan(ctx context.Context, days int) (int64, error) {
+	return 0, nil
+}
+
+// memoryHistoryRepo is a MetaHistoryRepository recording rollups
+type memoryHistoryRepo struct {
+	rollups  []int
+	rebuilds [][2]time.Time
+	err      error
+}
+
+func (r *memoryHistoryRepo) Rollup(ctx context.Context, retentionDays int) (int64, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	r.rollups = append(r.rollups, retentionDays)
+	return 0, nil
+}
+
+func (r *memoryHistoryRepo) Rebuild(ctx context.Context, from, to time.Time, retentionDays int) (int64, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	r.rebuilds = append(r.rebuilds, [2]time.Time{from, to})
+	return 0, nil
+}
+
+func (r *memoryHistoryRepo) GetDeckHistory(ctx context.Context, signature string, interval string, days int) ([]*models.DeckHistoryPoint, error) {
+	return nil, nil
+}
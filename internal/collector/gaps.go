@@ -0,0 +1,32 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/leopoldhub/royal-api-personal/pkg/supercell"
+)
+
+// DetectGap reports whether battles were lost between two fetches of a player:
+// when the oldest battle of the new battlelog is newer than the latest battle
+// seen at the previous fetch, everything played in between has rolled out of
+// the 25-battle window. lastSeen covers every mode, as the window does, so
+// non-ladder battles are not mistaken for a gap. A zero lastSeen (player never
+// fetched) cannot be checked and returns false.
+func DetectGap(lastSeen time.Time, battles []supercell.BattleRaw) bool {
+	if lastSeen.IsZero() || len(battles) == 0 {
+		return false
+	}
+
+	var oldest time.Time
+	for _, raw := range battles {
+		battleTime, err := ParseBattleTime(raw.BattleTime)
+		if err != nil {
+			continue
+		}
+		if oldest.IsZero() || battleTime.Before(oldest) {
+			oldest = battleTime
+		}
+	}
+
+	return !oldest.IsZero() && oldest.After(lastSeen)
+}
@@ -0,0 +1,48 @@
+package collector
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDetectGap(t *testing.T) {
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		lastSeen    time.Time
+		battleTimes []time.Time
+		want        bool
+	}{
+		{
+			name:        "window overlaps last seen battle",
+			lastSeen:    now.Add(-3 * time.Hour),
+			battleTimes: []time.Time{now.Add(-1 * time.Hour), now.Add(-3 * time.Hour), now.Add(-5 * time.Hour)},
+			want:        false,
+		},
+		{
+			name:        "oldest battle newer than last seen",
+			lastSeen:    now.Add(-10 * time.Hour),
+			battleTimes: []time.Time{now.Add(-1 * time.Hour), now.Add(-2 * time.Hour)},
+			want:        true,
+		},
+		{
+			name:        "player never fetched",
+			battleTimes: []time.Time{now.Add(-1 * time.Hour)},
+			want:        false,
+		},
+		{
+			name:     "empty battlelog",
+			lastSeen: now.Add(-10 * time.Hour),
+			want:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectGap(tt.lastSeen, battlesAt(tt.battleTimes...)); got != tt.want {
+				t.Errorf("DetectGap() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -7,7 +7,17 @@ import (
 
 // Service orchestrates the collection process
 type Service interface {
+	// Collect fetches every tracked player, resuming an interrupted run if any
 	Collect(ctx context.Context) (*CollectResult, error)
+
+	// CollectWithOptions fetches every tracked player, opts.Fresh abandons an interrupted run
+	CollectWithOptions(ctx context.Context, opts CollectOptions) (*CollectResult, error)
+
+	// CollectDue fetches only the players whose adaptive polling schedule is due
+	CollectDue(ctx context.Context) (*CollectResult, error)
+
+	// Reparse rebuilds battles and meta decks from the raw battlelog archive
+	Reparse(ctx context.Context) (*ReparseResult, error)
 }
 
 // CollectResult contains statistics about a collection run
@@ -15,9 +25,23 @@ type CollectResult struct {
 	PlayersProcessed int
 	BattlesCollected int
 	BattlesFiltered  int
-	BattlesStored    int
+	BattlesParsed    int
+	BattlesStored    int            // battles actually new in database
+	BattlesDuplicate int            // parsed battles that were already stored
+	BattlesSkipped   int            // parsed battles the repository could not encode or partition
+	PlayersChecked   int            // players fetched before, eligible for gap detection
+	PlayersWithGaps  int            // players whose battlelog overflowed since the last fetch
+	Rejections       map[string]int // parser rejections per reason
 	Errors           []error
 	Duration         time.Duration
 	StartedAt        time.Time
 	CompletedAt      time.Time
 }
+
+// Coverage returns the percentage of checked players without a battlelog gap
+func (r *CollectResult) Coverage() float64 {
+	if r.PlayersChecked == 0 {
+		return 100
+	}
+	return float64(r.PlayersChecked-r.PlayersWithGaps) / float64(r.PlayersChecked) * 100
+}
@@ -0,0 +1,46 @@
+package collector
+
+import (
+	"context"
+	"time"
+
+	"github.com/leopoldhub/royal-api-personal/internal/metrics"
+)
+
+// Outcomes of a collection run in royal_collector_runs_total
+const (
+	runCompleted   = "completed"
+	runFailed      = "failed"
+	runInterrupted = "interrupted"
+)
+
+var (
+	collectorRuns = metrics.Default.Counter("royal_collector_runs_total",
+		"Collection runs by outcome: completed, failed or interrupted", "status")
+	collectorRunDuration = metrics.Default.Histogram("royal_collector_run_duration_seconds",
+		"Duration of collection runs, interrupted runs included",
+		[]float64{30, 60, 120, 300, 600, 900, 1800, 3600, 7200}, "status")
+	collectorLastSuccess = metrics.Default.Gauge("royal_collector_last_success_timestamp_seconds",
+		"Unix time of the last completed collection run")
+	collectorBattlesStored = metrics.Default.Counter("royal_collector_battles_stored_total",
+		"Battles inserted in database, duplicates excluded")
+	collectorRejections = metrics.Default.Counter("royal_collector_parse_rejections_total",
+		"Battles rejected by the parser by reason", "reason")
+)
+
+// observeRun records the outcome of a collection run started at startedAt
+func observeRun(ctx context.Context, startedAt time.Time, runErr error) {
+	status := runCompleted
+	switch {
+	case ctx.Err() != nil:
+		status = runInterrupted
+	case runErr != nil:
+		status = runFailed
+	}
+
+	collectorRuns.Inc(status)
+	collectorRunDuration.Observe(time.Since(startedAt).Seconds(), status)
+	if status == runCompleted {
+		collectorLastSuccess.Set(float64(time.Now().Unix()))
+	}
+}
@@ -0,0 +1,32 @@
+package collector
+
+import (
+	"flag"
+	"fmt"
+	"io"
+)
+
+// CollectOptions controls how a collection run starts
+type CollectOptions struct {
+	// Fresh abandons an interrupted run instead of resuming it
+	Fresh bool
+}
+
+// ParseCollectArgs parses the flags of the collect commands: --resume (default)
+// continues an interrupted run from its checkpoint, --fresh starts over
+func ParseCollectArgs(args []string) (CollectOptions, error) {
+	fs := flag.NewFlagSet("collect", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	resume := fs.Bool("resume", false, "resume the last interrupted collection (default)")
+	fresh := fs.Bool("fresh", false, "abandon any interrupted collection and start over")
+
+	if err := fs.Parse(args); err != nil {
+		return CollectOptions{}, err
+	}
+	if *resume && *fresh {
+		return CollectOptions{}, fmt.Errorf("--resume and --fresh are mutually exclusive")
+	}
+
+	return CollectOptions{Fresh: *fresh}, nil
+}
@@ -0,0 +1,29 @@
+package collector
+
+import "testing"
+
+func TestParseCollectArgs(t *testing.T) {
+	tests := []struct {
+		args      []string
+		wantFresh bool
+		wantErr   bool
+	}{
+		{args: nil, wantFresh: false},
+		{args: []string{"--resume"}, wantFresh: false},
+		{args: []string{"--fresh"}, wantFresh: true},
+		{args: []string{"-fresh"}, wantFresh: true},
+		{args: []string{"--resume", "--fresh"}, wantErr: true},
+		{args: []string{"--unknown"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		opts, err := ParseCollectArgs(tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseCollectArgs(%v) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			continue
+		}
+		if opts.Fresh != tt.wantFresh {
+			t.Errorf("ParseCollectArgs(%v).Fresh = %v, want %v", tt.args, opts.Fresh, tt.wantFresh)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/leopoldhub/royal-api-personal/internal/errors"
 	"github.com/leopoldhub/royal-api-personal/internal/models"
 	"github.com/leopoldhub/royal-api-personal/pkg/supercell"
 	"github.com/leopoldhub/royal-api-personal/pkg/utils"
@@ -29,22 +30,41 @@ func FilterPvPLadder(battles []supercell.BattleRaw) []supercell.BattleRaw {
 	return filtered
 }
 
-// ParseBattle converts a raw battle from API to internal Battle model
+// Rejection reasons reported by ParseBattle in errors.ParseError
+const (
+	RejectMissingTeam     = "missing_team"
+	RejectMissingOpponent = "missing_opponent"
+	RejectCardCount       = "invalid_card_count"
+	RejectBattleTime      = "invalid_battle_time"
+)
+
+// ParseBattle converts a raw battle from API to internal Battle model.
+// Rejected battles return a *errors.ParseError carrying the reason.
 func ParseBattle(raw supercell.BattleRaw) (*models.Battle, error) {
-	if len(raw.Team) == 0 || len(raw.Opponent) == 0 {
-		return nil, nil
+	if len(raw.Team) == 0 {
+		return nil, &errors.ParseError{Reason: RejectMissingTeam, Message: "battle has no team"}
+	}
+	if len(raw.Opponent) == 0 {
+		return nil, &errors.ParseError{Reason: RejectMissingOpponent, Message: "battle has no opponent"}
 	}
 
 	player := raw.Team[0]
 	opponent := raw.Opponent[0]
 
 	if len(player.Cards) != 8 {
-		return nil, nil
+		return nil, &errors.ParseError{
+			Reason:  RejectCardCount,
+			Message: fmt.Sprintf("expected 8 cards, got %d", len(player.Cards)),
+		}
 	}
 
 	battleTime, err := ParseBattleTime(raw.BattleTime)
 	if err != nil {
-		return nil, err
+		return nil, &errors.ParseError{
+			Reason:  RejectBattleTime,
+			Message: fmt.Sprintf("invalid battle time %q", raw.BattleTime),
+			Err:     err,
+		}
 	}
 
 	var deckCards [8]models.Card
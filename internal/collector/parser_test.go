@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/leopoldhub/royal-api-personal/internal/errors"
 	"github.com/leopoldhub/royal-api-personal/pkg/supercell"
 )
 
@@ -133,9 +134,12 @@ func TestParseBattle(t *testing.T) {
 }
 
 func TestParseBattle_InvalidData(t *testing.T) {
+	eightCards := make([]supercell.CardRaw, 8)
+
 	tests := []struct {
-		name string
-		raw  supercell.BattleRaw
+		name       string
+		raw        supercell.BattleRaw
+		wantReason string
 	}{
 		{
 			name: "empty team",
@@ -143,6 +147,7 @@ func TestParseBattle_InvalidData(t *testing.T) {
 				Team:     []supercell.TeamMember{},
 				Opponent: []supercell.TeamMember{{Tag: "#ABC"}},
 			},
+			wantReason: RejectMissingTeam,
 		},
 		{
 			name: "empty opponent",
@@ -150,6 +155,7 @@ func TestParseBattle_InvalidData(t *testing.T) {
 				Team:     []supercell.TeamMember{{Tag: "#2PP"}},
 				Opponent: []supercell.TeamMember{},
 			},
+			wantReason: RejectMissingOpponent,
 		},
 		{
 			name: "invalid card count",
@@ -162,6 +168,16 @@ func TestParseBattle_InvalidData(t *testing.T) {
 				},
 				Opponent: []supercell.TeamMember{{Tag: "#ABC"}},
 			},
+			wantReason: RejectCardCount,
+		},
+		{
+			name: "invalid battle time",
+			raw: supercell.BattleRaw{
+				BattleTime: "2024-01-10",
+				Team:       []supercell.TeamMember{{Tag: "#2PP", Cards: eightCards}},
+				Opponent:   []supercell.TeamMember{{Tag: "#ABC"}},
+			},
+			wantReason: RejectBattleTime,
 		},
 	}
 
@@ -171,8 +187,13 @@ func TestParseBattle_InvalidData(t *testing.T) {
 			if battle != nil {
 				t.Errorf("expected nil battle for invalid data, got %v", battle)
 			}
-			if err != nil {
-				t.Logf("got expected error: %v", err)
+
+			parseErr, ok := err.(*errors.ParseError)
+			if !ok {
+				t.Fatalf("expected *errors.ParseError, got %T (%v)", err, err)
+			}
+			if parseErr.Reason != tt.wantReason {
+				t.Errorf("Reason = %s, want %s", parseErr.Reason, tt.wantReason)
 			}
 		})
 	}
@@ -0,0 +1,185 @@
+package collector
+
+import (
+	"context"
+	"hash/fnv"
+	"log"
+	"time"
+
+	"github.com/leopoldhub/royal-api-personal/internal/models"
+	"github.com/leopoldhub/royal-api-personal/pkg/supercell"
+)
+
+const (
+	// BattlelogWindow is the number of battles returned by GetBattlelog
+	BattlelogWindow = 25
+
+	// targetWindowFill is the share of the window we expect to be filled at the
+	// next fetch, the remainder is headroom for bursts of activity
+	targetWindowFill = 0.6
+
+	// MinPollInterval and MaxPollInterval bound the delay between two fetches of a player
+	MinPollInterval = time.Hour
+	MaxPollInterval = 24 * time.Hour
+
+	// DefaultCollectTick is the scheduler tick when not configured
+	DefaultCollectTick = 15 * time.Minute
+)
+
+// UpdateSchedule computes the next polling state of a player from a freshly fetched battlelog.
+// prev may be nil for a player that was never fetched.
+func UpdateSchedule(prev *models.PlayerSchedule, tag string, battles []supercell.BattleRaw, now time.Time) *models.PlayerSchedule {
+	schedule := &models.PlayerSchedule{
+		PlayerTag:     tag,
+		LastFetchedAt: now,
+	}
+
+	var since time.Time
+	if prev != nil {
+		since = prev.LastBattleTime
+		schedule.LastBattleTime = prev.LastBattleTime
+	}
+
+	newBattles := 0
+	var oldest time.Time
+	for _, raw := range battles {
+		battleTime, err := ParseBattleTime(raw.BattleTime)
+		if err != nil {
+			continue
+		}
+		if battleTime.After(since) {
+			newBattles++
+		}
+		if battleTime.After(schedule.LastBattleTime) {
+			schedule.LastBattleTime = battleTime
+		}
+		if oldest.IsZero() || battleTime.Before(oldest) {
+			oldest = battleTime
+		}
+	}
+
+	schedule.WindowFill = float64(newBattles) / BattlelogWindow
+
+	// Observed rate: new battles since the previous fetch, or the whole window
+	// over its time span on the first fetch
+	var observed float64
+	switch {
+	case prev != nil && !prev.LastFetchedAt.IsZero():
+		observed = float64(newBattles) / hoursAtLeastOne(now.Sub(prev.LastFetchedAt))
+	case !oldest.IsZero():
+		observed = float64(newBattles) / hoursAtLeastOne(now.Sub(oldest))
+	}
+
+	rate := observed
+	if prev != nil && prev.BattlesPerHour > 0 {
+		rate = (prev.BattlesPerHour + observed) / 2
+		// A full window only gives a lower bound of the real rate
+		if newBattles >= BattlelogWindow && observed > rate {
+			rate = observed
+		}
+	}
+	schedule.BattlesPerHour = rate
+
+	interval := PollInterval(rate)
+	schedule.NextFetchAt = now.Add(interval - spreadOffset(tag, interval))
+
+	return schedule
+}
+
+// PollInterval returns the delay after which a player playing rate battles per hour
+// is expected to have filled targetWindowFill of the battlelog window
+func PollInterval(rate float64) time.Duration {
+	if rate <= 0 {
+		return MaxPollInterval
+	}
+
+	hours := targetWindowFill * BattlelogWindow / rate
+	interval := time.Duration(hours * float64(time.Hour))
+
+	if interval < MinPollInterval {
+		return MinPollInterval
+	}
+	if interval > MaxPollInterval {
+		return MaxPollInterval
+	}
+	return interval
+}
+
+// DueTags returns the tags whose next fetch is due, players never fetched first
+func DueTags(tags []string, schedules map[string]*models.PlayerSchedule, now time.Time) []string {
+	var fresh, due []string
+	for _, tag := range tags {
+		schedule, ok := schedules[tag]
+		switch {
+		case !ok:
+			fresh = append(fresh, tag)
+		case !schedule.NextFetchAt.After(now):
+			due = append(due, tag)
+		}
+	}
+	return append(fresh, due...)
+}
+
+// spreadOffset returns a stable per-player offset in [0, interval/10) so that players
+// sharing the same rate do not all become due on the same tick
+func spreadOffset(tag string, interval time.Duration) time.Duration {
+	window := interval / 10
+	if window <= 0 {
+		return 0
+	}
+	h := fnv.New32a()
+	h.Write([]byte(tag))
+	return time.Duration(h.Sum32()) % window
+}
+
+func hoursAtLeastOne(d time.Duration) float64 {
+	if d < time.Hour {
+		return 1
+	}
+	return d.Hours()
+}
+
+// Scheduler runs CollectDue at a fixed tick so that battlelog fetches are spread
+// over the day instead of one big batch
+type Scheduler struct {
+	service Service
+	tick    time.Duration
+	logger  *log.Logger
+}
+
+// NewScheduler creates a scheduler polling due players every tick, a tick
+// below one minute falling back to DefaultCollectTick
+func NewScheduler(service Service, tick time.Duration, logger *log.Logger) *Scheduler {
+	if logger == nil {
+		logger = log.Default()
+	}
+	if tick < time.Minute {
+		tick = DefaultCollectTick
+	}
+	return &Scheduler{
+		service: service,
+		tick:    tick,
+		logger:  logger,
+	}
+}
+
+// Run collects due players until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) error {
+	s.logger.Printf("Starting adaptive collection loop (tick %v)", s.tick)
+
+	for {
+		result, err := s.service.CollectDue(ctx)
+		if err != nil {
+			s.logger.Printf("Scheduled collection failed: %v", err)
+		} else if result.PlayersProcessed > 0 {
+			s.logger.Printf("Scheduled collection: %d players, %d battles stored in %v",
+				result.PlayersProcessed, result.BattlesStored, result.Duration)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(s.tick):
+		}
+	}
+}
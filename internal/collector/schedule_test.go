@@ -0,0 +1,134 @@
+package collector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leopoldhub/royal-api-personal/internal/models"
+	"github.com/leopoldhub/royal-api-personal/pkg/supercell"
+)
+
+func battlesAt(times ...time.Time) []supercell.BattleRaw {
+	battles := make([]supercell.BattleRaw, len(times))
+	for i, t := range times {
+		battles[i] = supercell.BattleRaw{BattleTime: t.UTC().Format("20060102T150405.000Z")}
+	}
+	return battles
+}
+
+func TestPollInterval(t *testing.T) {
+	tests := []struct {
+		rate float64
+		want time.Duration
+	}{
+		{0, MaxPollInterval},
+		{0.1, MaxPollInterval},
+		{1, 15 * time.Hour},
+		{5, 3 * time.Hour},
+		{100, MinPollInterval},
+	}
+
+	for _, tt := range tests {
+		if got := PollInterval(tt.rate); got != tt.want {
+			t.Errorf("PollInterval(%v) = %v, want %v", tt.rate, got, tt.want)
+		}
+	}
+}
+
+func TestUpdateSchedule_FirstFetch(t *testing.T) {
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	battles := battlesAt(
+		now.Add(-1*time.Hour),
+		now.Add(-2*time.Hour),
+		now.Add(-3*time.Hour),
+		now.Add(-4*time.Hour),
+	)
+
+	schedule := UpdateSchedule(nil, "#2PP", battles, now)
+
+	if schedule.BattlesPerHour != 1 {
+		t.Errorf("BattlesPerHour = %v, want 1", schedule.BattlesPerHour)
+	}
+	if !schedule.LastBattleTime.Equal(now.Add(-1 * time.Hour)) {
+		t.Errorf("LastBattleTime = %v, want newest battle", schedule.LastBattleTime)
+	}
+	if schedule.WindowFill != 4.0/BattlelogWindow {
+		t.Errorf("WindowFill = %v, want %v", schedule.WindowFill, 4.0/BattlelogWindow)
+	}
+
+	interval := schedule.NextFetchAt.Sub(now)
+	if interval > PollInterval(1) || interval < PollInterval(1)*9/10 {
+		t.Errorf("next fetch in %v, want within 10%% below %v", interval, PollInterval(1))
+	}
+}
+
+func TestUpdateSchedule_OverflowRaisesRate(t *testing.T) {
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	prev := &models.PlayerSchedule{
+		PlayerTag:      "#2PP",
+		BattlesPerHour: 0.5,
+		LastBattleTime: now.Add(-30 * time.Hour),
+		LastFetchedAt:  now.Add(-5 * time.Hour),
+	}
+
+	times := make([]time.Time, BattlelogWindow)
+	for i := range times {
+		times[i] = now.Add(-time.Duration(i*10) * time.Minute)
+	}
+
+	schedule := UpdateSchedule(prev, "#2PP", battlesAt(times...), now)
+
+	if schedule.WindowFill != 1 {
+		t.Errorf("WindowFill = %v, want 1", schedule.WindowFill)
+	}
+	if schedule.BattlesPerHour != 5 {
+		t.Errorf("BattlesPerHour = %v, want observed lower bound 5", schedule.BattlesPerHour)
+	}
+	if schedule.NextFetchAt.Sub(now) > PollInterval(5) {
+		t.Errorf("expected next fetch within %v, got %v", PollInterval(5), schedule.NextFetchAt.Sub(now))
+	}
+}
+
+func TestUpdateSchedule_IdlePlayer(t *testing.T) {
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	last := now.Add(-48 * time.Hour)
+	prev := &models.PlayerSchedule{
+		PlayerTag:      "#2PP",
+		BattlesPerHour: 0.2,
+		LastBattleTime: last,
+		LastFetchedAt:  now.Add(-24 * time.Hour),
+	}
+
+	schedule := UpdateSchedule(prev, "#2PP", battlesAt(last), now)
+
+	if schedule.WindowFill != 0 {
+		t.Errorf("WindowFill = %v, want 0", schedule.WindowFill)
+	}
+	if schedule.BattlesPerHour != 0.1 {
+		t.Errorf("BattlesPerHour = %v, want smoothed 0.1", schedule.BattlesPerHour)
+	}
+	if !schedule.LastBattleTime.Equal(last) {
+		t.Errorf("LastBattleTime = %v, want %v", schedule.LastBattleTime, last)
+	}
+}
+
+func TestDueTags(t *testing.T) {
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	schedules := map[string]*models.PlayerSchedule{
+		"#DUE":    {PlayerTag: "#DUE", NextFetchAt: now.Add(-time.Minute)},
+		"#LATER":  {PlayerTag: "#LATER", NextFetchAt: now.Add(time.Hour)},
+		"#EXACTS": {PlayerTag: "#EXACTS", NextFetchAt: now},
+	}
+
+	got := DueTags([]string{"#DUE", "#LATER", "#NEW", "#EXACTS"}, schedules, now)
+	want := []string{"#NEW", "#DUE", "#EXACTS"}
+
+	if len(got) != len(want) {
+		t.Fatalf("DueTags() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("DueTags()[%d] = %s, want %s", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package collector
+
+import (
+	"context"
+
+	"github.com/leopoldhub/royal-api-personal/internal/database/repository"
+	"github.com/leopoldhub/royal-api-personal/internal/models"
+)
+
+// defaultBatchSize is the number of battles committed per BatchInsert call
+const defaultBatchSize = 500
+
+// commitFunc is called after each successful batch with the players whose
+// battles are now fully committed and the insert summary of the batch
+type commitFunc func(ctx context.Context, playerTags []string, summary models.InsertSummary) error
+
+// batchWriter buffers parsed battles and commits them in batches of size.
+// It is used from a single goroutine.
+type batchWriter struct {
+	repo     repository.BattleRepository
+	size     int
+	buffer   []*models.Battle
+	pending  []string
+	onCommit commitFunc
+
+	summary       models.InsertSummary
+	failed        int
+	failedBatches int
+	lastErr       error
+}
+
+func newBatchWriter(repo repository.BattleRepository, size int, onCommit commitFunc) *batchWriter {
+	if size < 1 {
+		size = defaultBatchSize
+	}
+	return &batchWriter{
+		repo:     repo,
+		size:     size,
+		buffer:   make([]*models.Battle, 0, size),
+		onCommit: onCommit,
+	}
+}
+
+// Add buffers the battles of a player and commits every full batch. A failed
+// batch is dropped and its error returned, the writer stays usable for the
+// following batches.
+func (w *batchWriter) Add(ctx context.Context, playerTag string, battles []*models.Battle) error {
+	var firstErr error
+	for _, battle := range battles {
+		w.buffer = append(w.buffer, battle)
+		if len(w.buffer) >= w.size {
+			if err := w.Flush(ctx); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	// The player is complete once its last battle is committed, i.e. at the next
+	// flush. A player with a failed batch is not, it is fetched again on resume.
+	if firstErr == nil {
+		w.pending = append(w.pending, playerTag)
+	}
+	return firstErr
+}
+
+// Flush commits the buffered battles
+func (w *batchWriter) Flush(ctx context.Context) error {
+	if len(w.buffer) == 0 && len(w.pending) == 0 {
+		return nil
+	}
+
+	batch := w.buffer
+	tags := w.pending
+	w.buffer = make([]*models.Battle, 0, w.size)
+	w.pending = nil
+
+	var summary models.InsertSummary
+	if len(batch) > 0 {
+		var err error
+		summary, err = w.repo.BatchInsert(ctx, batch)
+		if err != nil {
+			w.failed += len(batch)
+			w.failedBatches++
+			w.lastErr = err
+			return err
+		}
+		w.summary.Add(summary)
+	}
+
+	if w.onCommit != nil {
+		return w.onCommit(ctx, tags, summary)
+	}
+	return nil
+}
@@ -4,20 +4,37 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+
+	"github.com/leopoldhub/royal-api-personal/internal/models"
 )
 
 // Config holds application configuration
 type Config struct {
 	SupercellAPIKey  string
+	SupercellSource  string
+	SupercellBaseURL string
+	FixturesDir      string
+	SupercellCache   string
+	CacheDir         string
+	ArchiveRaw       bool
 	APIToken         string
 	PostgresHost     string
 	PostgresPort     int
 	PostgresDB       string
 	PostgresUser     string
 	PostgresPassword string
+	MigrationsPath   string
 	TopPlayersLimit  int
 	APIPort          int
+	MetricsPort      int // port of /metrics in collect-loop, 0 to disable, the API serving it on APIPort
+	RateLimitPublic  int // requests per minute per IP address on public routes, 0 for unlimited
+	RateLimitAuth    int // requests per minute per IP address on protected routes, before authentication
+	RateLimitDecks   int // requests per minute per client on read:decks routes
+	RateLimitStats   int // requests per minute per client on read:stats routes
+	RateLimitMetrics int // requests per minute per client on read:metrics routes
+	RateLimitAdmin   int // requests per minute per client on admin routes
 	RetentionDays    int
+	CollectTick      int // minutes between two checks of collect-loop for due players
 	LogLevel         string
 }
 
@@ -25,15 +42,30 @@ type Config struct {
 func LoadFromEnv() (*Config, error) {
 	cfg := &Config{
 		SupercellAPIKey:  getEnv("SUPERCELL_API_KEY", ""),
+		SupercellSource:  getEnv("SUPERCELL_SOURCE", "api"),
+		SupercellBaseURL: getEnv("SUPERCELL_BASE_URL", ""),
+		FixturesDir:      getEnv("FIXTURES_DIR", ""),
+		SupercellCache:   getEnv("SUPERCELL_CACHE", "none"),
+		CacheDir:         getEnv("CACHE_DIR", ".cache/supercell"),
+		ArchiveRaw:       getEnvBool("ARCHIVE_RAW", false),
 		APIToken:         getEnv("API_TOKEN", ""),
 		PostgresHost:     getEnv("POSTGRES_HOST", "localhost"),
 		PostgresPort:     getEnvInt("POSTGRES_PORT", 5432),
 		PostgresDB:       getEnv("POSTGRES_DB", "royale_api"),
 		PostgresUser:     getEnv("POSTGRES_USER", "royale"),
 		PostgresPassword: getEnv("POSTGRES_PASSWORD", ""),
+		MigrationsPath:   getEnv("MIGRATIONS_PATH", ""),
 		TopPlayersLimit:  getEnvInt("TOP_PLAYERS_LIMIT", 1000),
 		APIPort:          getEnvInt("API_PORT", 8080),
+		MetricsPort:      getEnvInt("METRICS_PORT", 9091),
+		RateLimitPublic:  getEnvInt("RATE_LIMIT_PUBLIC", 60),
+		RateLimitAuth:    getEnvInt("RATE_LIMIT_AUTH", 300),
+		RateLimitDecks:   getEnvInt("RATE_LIMIT_READ_DECKS", 120),
+		RateLimitStats:   getEnvInt("RATE_LIMIT_READ_STATS", 60),
+		RateLimitMetrics: getEnvInt("RATE_LIMIT_READ_METRICS", 60),
+		RateLimitAdmin:   getEnvInt("RATE_LIMIT_ADMIN", 30),
 		RetentionDays:    getEnvInt("RETENTION_DAYS", 7),
+		CollectTick:      getEnvInt("COLLECT_TICK_MINUTES", 15),
 		LogLevel:         getEnv("LOG_LEVEL", "info"),
 	}
 
@@ -46,8 +78,22 @@ func LoadFromEnv() (*Config, error) {
 
 // Validate checks if required configuration values are set
 func (c *Config) Validate() error {
-	if c.SupercellAPIKey == "" {
-		return fmt.Errorf("SUPERCELL_API_KEY is required")
+	switch c.SupercellSource {
+	case "", "api", "royaleapi":
+		if c.SupercellAPIKey == "" {
+			return fmt.Errorf("SUPERCELL_API_KEY is required")
+		}
+	case "fixtures":
+		if c.FixturesDir == "" {
+			return fmt.Errorf("FIXTURES_DIR is required when SUPERCELL_SOURCE=fixtures")
+		}
+	default:
+		return fmt.Errorf("SUPERCELL_SOURCE must be one of api, royaleapi, fixtures")
+	}
+	switch c.SupercellCache {
+	case "", "none", "memory", "disk":
+	default:
+		return fmt.Errorf("SUPERCELL_CACHE must be one of none, memory, disk")
 	}
 	if c.APIToken == "" {
 		return fmt.Errorf("API_TOKEN is required")
@@ -58,6 +104,16 @@ func (c *Config) Validate() error {
 	if c.TopPlayersLimit < 1 || c.TopPlayersLimit > 1000 {
 		return fmt.Errorf("TOP_PLAYERS_LIMIT must be between 1 and 1000")
 	}
+	if c.RetentionDays < 0 {
+		return fmt.Errorf("RETENTION_DAYS must not be negative")
+	}
+	if c.CollectTick < 0 {
+		return fmt.Errorf("COLLECT_TICK_MINUTES must not be negative")
+	}
+	if c.RateLimitPublic < 0 || c.RateLimitAuth < 0 || c.RateLimitDecks < 0 || c.RateLimitStats < 0 ||
+		c.RateLimitMetrics < 0 || c.RateLimitAdmin < 0 {
+		return fmt.Errorf("RATE_LIMIT_* must not be negative")
+	}
 	return nil
 }
 
@@ -73,6 +129,20 @@ func (c *Config) PostgresDSN() string {
 	)
 }
 
+// RateLimits returns the requests per minute allowed per route scope, the
+// empty scope standing for public routes and models.RateLimitAuth for the per
+// IP limit of protected routes
+func (c *Config) RateLimits() map[string]int {
+	return map[string]int{
+		"":                      c.RateLimitPublic,
+		models.RateLimitAuth:    c.RateLimitAuth,
+		models.ScopeReadDecks:   c.RateLimitDecks,
+		models.ScopeReadStats:   c.RateLimitStats,
+		models.ScopeReadMetrics: c.RateLimitMetrics,
+		models.ScopeAdmin:       c.RateLimitAdmin,
+	}
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -88,3 +158,12 @@ func getEnvInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
@@ -3,6 +3,8 @@ package config
 import (
 	"os"
 	"testing"
+
+	"github.com/leopoldhub/royal-api-personal/internal/models"
 )
 
 func TestLoadFromEnv(t *testing.T) {
@@ -137,6 +139,93 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "fixtures source without api key",
+			config: &Config{
+				SupercellSource:  "fixtures",
+				FixturesDir:      "testdata/fixtures",
+				APIToken:         "token",
+				PostgresPassword: "pass",
+				TopPlayersLimit:  500,
+			},
+			wantErr: false,
+		},
+		{
+			name: "fixtures source without directory",
+			config: &Config{
+				SupercellSource:  "fixtures",
+				APIToken:         "token",
+				PostgresPassword: "pass",
+				TopPlayersLimit:  500,
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown source",
+			config: &Config{
+				SupercellAPIKey:  "key",
+				SupercellSource:  "scraper",
+				APIToken:         "token",
+				PostgresPassword: "pass",
+				TopPlayersLimit:  500,
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown cache store",
+			config: &Config{
+				SupercellAPIKey:  "key",
+				SupercellCache:   "redis",
+				APIToken:         "token",
+				PostgresPassword: "pass",
+				TopPlayersLimit:  500,
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative rate limit",
+			config: &Config{
+				SupercellAPIKey:  "key",
+				APIToken:         "token",
+				PostgresPassword: "pass",
+				TopPlayersLimit:  500,
+				RateLimitDecks:   -1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative metrics rate limit",
+			config: &Config{
+				SupercellAPIKey:  "key",
+				APIToken:         "token",
+				PostgresPassword: "pass",
+				TopPlayersLimit:  500,
+				RateLimitMetrics: -1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative RetentionDays",
+			config: &Config{
+				SupercellAPIKey:  "key",
+				APIToken:         "token",
+				PostgresPassword: "pass",
+				TopPlayersLimit:  500,
+				RetentionDays:    -1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative CollectTick",
+			config: &Config{
+				SupercellAPIKey:  "key",
+				APIToken:         "token",
+				PostgresPassword: "pass",
+				TopPlayersLimit:  500,
+				CollectTick:      -5,
+			},
+			wantErr: true,
+		},
 		{
 			name: "TopPlayersLimit too low",
 			config: &Config{
@@ -158,3 +247,51 @@ func TestConfig_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadFromEnv_ArchiveRaw(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("SUPERCELL_API_KEY", "key")
+	os.Setenv("API_TOKEN", "token")
+	os.Setenv("POSTGRES_PASSWORD", "pass")
+
+	cfg, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv() error = %v", err)
+	}
+	if cfg.ArchiveRaw {
+		t.Error("expected the raw archive to be disabled by default")
+	}
+
+	os.Setenv("ARCHIVE_RAW", "true")
+	cfg, err = LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv() error = %v", err)
+	}
+	if !cfg.ArchiveRaw {
+		t.Error("expected ARCHIVE_RAW=true to enable the raw archive")
+	}
+}
+
+func TestLoadFromEnv_RateLimitMetrics(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("SUPERCELL_API_KEY", "key")
+	os.Setenv("API_TOKEN", "token")
+	os.Setenv("POSTGRES_PASSWORD", "pass")
+
+	cfg, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv() error = %v", err)
+	}
+	if got := cfg.RateLimits()[models.ScopeReadMetrics]; got != 60 {
+		t.Errorf("default read:metrics rate limit = %d, want 60", got)
+	}
+
+	os.Setenv("RATE_LIMIT_READ_METRICS", "12")
+	cfg, err = LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv() error = %v", err)
+	}
+	if got := cfg.RateLimits()[models.ScopeReadMetrics]; got != 12 {
+		t.Errorf("read:metrics rate limit = %d, want RATE_LIMIT_READ_METRICS 12", got)
+	}
+}
@@ -6,22 +6,17 @@ import (
 	"fmt"
 )
 
-// PurgeOldBattles removes battles older than the specified retention period
+// PurgeOldBattles removes battles older than the specified retention period by
+// dropping their daily partitions
 func PurgeOldBattles(ctx context.Context, db *sql.DB, retentionDays int) (int64, error) {
 	query := `
-		DELETE FROM battles 
-		WHERE battle_time < NOW() - INTERVAL '1 day' * $1
+		SELECT purge_battles_before(NOW() - INTERVAL '1 day' * $1)
 	`
 
-	result, err := db.ExecContext(ctx, query, retentionDays)
-	if err != nil {
+	var rowsAffected int64
+	if err := db.QueryRowContext(ctx, query, retentionDays).Scan(&rowsAffected); err != nil {
 		return 0, fmt.Errorf("failed to purge old battles: %w", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return 0, fmt.Errorf("failed to get rows affected: %w", err)
-	}
-
 	return rowsAffected, nil
 }
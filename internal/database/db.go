@@ -1,11 +1,9 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"os"
-	"path/filepath"
-	"strings"
 	"time"
 
 	"github.com/leopoldhub/royal-api-personal/internal/config"
@@ -30,37 +28,20 @@ func Connect(cfg *config.Config) (*sql.DB, error) {
 	return db, nil
 }
 
-// RunMigrations executes SQL migration files in order
+// RunMigrations applies the pending migrations. An empty migrationsPath uses the
+// migrations embedded in the binary.
 func RunMigrations(db *sql.DB, migrationsPath string) error {
-	files, err := filepath.Glob(filepath.Join(migrationsPath, "*.sql"))
+	loaded, err := LoadMigrations(MigrationsFS(migrationsPath))
 	if err != nil {
-		return fmt.Errorf("failed to read migration files: %w", err)
+		return err
 	}
 
-	if len(files) == 0 {
-		return fmt.Errorf("no migration files found in %s", migrationsPath)
+	if len(loaded) == 0 {
+		return fmt.Errorf("no migration files found in %q", migrationsPath)
 	}
 
-	for _, file := range files {
-		content, err := os.ReadFile(file)
-		if err != nil {
-			return fmt.Errorf("failed to read migration file %s: %w", file, err)
-		}
-
-		statements := strings.Split(string(content), ";")
-		for _, stmt := range statements {
-			stmt = strings.TrimSpace(stmt)
-			if stmt == "" || strings.HasPrefix(stmt, "--") {
-				continue
-			}
-
-			if _, err := db.Exec(stmt); err != nil {
-				return fmt.Errorf("failed to execute migration %s: %w", file, err)
-			}
-		}
-	}
-
-	return nil
+	_, err = NewMigrator(db, loaded).Up(context.Background())
+	return err
 }
 
 // Close closes the database connection gracefully
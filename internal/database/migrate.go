@@ -0,0 +1,363 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leopoldhub/royal-api-personal/migrations"
+)
+
+// Migration is a versioned schema change read from NNN_name.sql, with its
+// optional rollback NNN_name.down.sql
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string // sha256 of Up
+}
+
+// MigrationStatus reports whether a migration is applied
+type MigrationStatus struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// MigrationsFS returns the migrations embedded in the binary, or the directory
+// path when set, which lets development runs pick up unbuilt changes
+func MigrationsFS(path string) fs.FS {
+	if path == "" {
+		return migrations.FS
+	}
+	return os.DirFS(path)
+}
+
+// LoadMigrations reads the migrations of fsys ordered by version
+func LoadMigrations(fsys fs.FS) ([]*Migration, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migration files: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	downs := make(map[int]string)
+	for _, file := range files {
+		base := path.Base(file)
+		isDown := strings.HasSuffix(base, ".down.sql")
+		name := strings.TrimSuffix(strings.TrimSuffix(base, ".sql"), ".down")
+
+		prefix, _, _ := strings.Cut(name, "_")
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration file name %s: expected NNN_name.sql", base)
+		}
+
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration file %s: %w", base, err)
+		}
+
+		if isDown {
+			downs[version] = string(content)
+			continue
+		}
+		if existing, ok := byVersion[version]; ok {
+			return nil, fmt.Errorf("duplicate migration version %d: %s and %s", version, existing.Name, name)
+		}
+
+		sum := sha256.Sum256(content)
+		byVersion[version] = &Migration{
+			Version:  version,
+			Name:     name,
+			Up:       string(content),
+			Checksum: hex.EncodeToString(sum[:]),
+		}
+	}
+
+	for version, down := range downs {
+		migration, ok := byVersion[version]
+		if !ok {
+			return nil, fmt.Errorf("down migration %d has no matching up migration", version)
+		}
+		migration.Down = down
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		migrations = append(migrations, migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Migrator applies and rolls back migrations, tracking them in schema_migrations
+type Migrator struct {
+	db         *sql.DB
+	migrations []*Migration
+}
+
+// NewMigrator creates a migrator for migrations ordered by version
+func NewMigrator(db *sql.DB, migrations []*Migration) *Migrator {
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+	}
+}
+
+type appliedMigration struct {
+	checksum  string
+	appliedAt time.Time
+}
+
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			checksum VARCHAR(64) NOT NULL,
+			applied_at TIMESTAMP NOT NULL DEFAULT NOW()
+		)
+	`
+	if _, err := m.db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	return nil
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[int]appliedMigration, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+
+	rows, err := m.db.QueryContext(ctx, "SELECT version, checksum, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]appliedMigration)
+	for rows.Next() {
+		var version int
+		var record appliedMigration
+		if err := rows.Scan(&version, &record.checksum, &record.appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+		}
+		applied[version] = record
+	}
+
+	return applied, rows.Err()
+}
+
+// verify fails when an applied migration file was modified after being applied
+func (m *Migrator) verify(applied map[int]appliedMigration) error {
+	for _, migration := range m.migrations {
+		record, ok := applied[migration.Version]
+		if ok && record.checksum != migration.Checksum {
+			return fmt.Errorf("migration %s was modified after being applied (checksum mismatch)", migration.Name)
+		}
+	}
+	return nil
+}
+
+// Status returns every known migration with its applied state
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		record, ok := applied[migration.Version]
+		statuses = append(statuses, MigrationStatus{
+			Migration: *migration,
+			Applied:   ok,
+			AppliedAt: record.appliedAt,
+		})
+	}
+	return statuses, nil
+}
+
+// Up applies pending migrations in order, each in its own transaction, and
+// returns the number applied
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if err := m.verify(applied); err != nil {
+		return 0, err
+	}
+	if err := m.adoptLegacy(ctx, applied); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		err := m.inTx(ctx, migration.Up, `
+			INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)
+		`, migration.Version, migration.Name, migration.Checksum)
+		if err != nil {
+			return count, fmt.Errorf("failed to apply migration %s: %w", migration.Name, err)
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// legacyVersion is the initial schema, applied without tracking by the
+// releases preceding schema_migrations
+const legacyVersion = 1
+
+// adoptLegacy records the initial schema as applied on a database created
+// before schema_migrations existed, instead of running it again: its CREATE
+// INDEX statements are not idempotent. Later migrations are.
+func (m *Migrator) adoptLegacy(ctx context.Context, applied map[int]appliedMigration) error {
+	if len(applied) > 0 || len(m.migrations) == 0 || m.migrations[0].Version != legacyVersion {
+		return nil
+	}
+
+	var exists bool
+	if err := m.db.QueryRowContext(ctx, "SELECT to_regclass('battles') IS NOT NULL").Scan(&exists); err != nil {
+		return fmt.Errorf("failed to detect an untracked schema: %w", err)
+	}
+	if !exists {
+		return nil
+	}
+
+	legacy := m.migrations[0]
+	_, err := m.db.ExecContext(ctx, `
+		INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)
+	`, legacy.Version, legacy.Name, legacy.Checksum)
+	if err != nil {
+		return fmt.Errorf("failed to adopt untracked schema: %w", err)
+	}
+	applied[legacy.Version] = appliedMigration{checksum: legacy.Checksum, appliedAt: time.Now()}
+	return nil
+}
+
+// Down rolls back the last steps applied migrations and returns the number rolled back
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if err := m.verify(applied); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := len(m.migrations) - 1; i >= 0 && count < steps; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		if migration.Down == "" {
+			return count, fmt.Errorf("migration %s has no down migration", migration.Name)
+		}
+
+		err := m.inTx(ctx, migration.Down, `
+			DELETE FROM schema_migrations WHERE version = $1
+		`, migration.Version)
+		if err != nil {
+			return count, fmt.Errorf("failed to roll back migration %s: %w", migration.Name, err)
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// inTx executes a migration script and its bookkeeping statement atomically.
+// The script is sent as a whole, so functions and DO blocks are supported.
+func (m *Migrator) inTx(ctx context.Context, script, record string, args ...any) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// MigrateCommand runs the migrate CLI command: status, up, or down [--steps N]
+// (one step by default). An empty migrationsPath uses the embedded migrations.
+// Output is written to out.
+func MigrateCommand(ctx context.Context, db *sql.DB, migrationsPath string, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: migrate status|up|down [--steps N]")
+	}
+
+	loaded, err := LoadMigrations(MigrationsFS(migrationsPath))
+	if err != nil {
+		return err
+	}
+	migrator := NewMigrator(db, loaded)
+
+	switch args[0] {
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied " + status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(out, "%03d %-40s %s\n", status.Version, status.Name, state)
+		}
+		return nil
+
+	case "up":
+		count, err := migrator.Up(ctx)
+		fmt.Fprintf(out, "Applied %d migrations\n", count)
+		return err
+
+	case "down":
+		fs := flag.NewFlagSet("migrate down", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		steps := fs.Int("steps", 1, "number of migrations to roll back")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *steps < 1 {
+			return fmt.Errorf("--steps must be at least 1")
+		}
+
+		count, err := migrator.Down(ctx, *steps)
+		fmt.Fprintf(out, "Rolled back %d migrations\n", count)
+		return err
+
+	default:
+		return fmt.Errorf("unknown migrate command %q: expected status, up or down", args[0])
+	}
+}
@@ -0,0 +1,110 @@
+package database
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeMigrations(t *testing.T, files map[string]string) fs.FS {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return os.DirFS(dir)
+}
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := writeMigrations(t, map[string]string{
+		"010_later.sql":       "CREATE TABLE later (id INT);",
+		"002_second.sql":      "CREATE TABLE second (id INT);",
+		"002_second.down.sql": "DROP TABLE second;",
+		"001_first.sql":       "CREATE TABLE first (id INT);",
+		"not_a_migration.txt": "ignored",
+		"001_first.down.sql":  "DROP TABLE first;",
+		"010_later.down.sql":  "DROP TABLE later;",
+	})
+
+	migrations, err := LoadMigrations(fsys)
+	if err != nil {
+		t.Fatalf("LoadMigrations() error = %v", err)
+	}
+
+	wantVersions := []int{1, 2, 10}
+	if len(migrations) != len(wantVersions) {
+		t.Fatalf("expected %d migrations, got %d", len(wantVersions), len(migrations))
+	}
+	for i, migration := range migrations {
+		if migration.Version != wantVersions[i] {
+			t.Errorf("migrations[%d].Version = %d, want %d", i, migration.Version, wantVersions[i])
+		}
+		if migration.Down == "" {
+			t.Errorf("migration %s: expected its down script to be loaded", migration.Name)
+		}
+		if len(migration.Checksum) != 64 {
+			t.Errorf("migration %s: unexpected checksum %q", migration.Name, migration.Checksum)
+		}
+	}
+	if migrations[1].Name != "002_second" || migrations[1].Down != "DROP TABLE second;" {
+		t.Errorf("unexpected second migration: %+v", migrations[1])
+	}
+}
+
+func TestLoadMigrations_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+	}{
+		{
+			name:  "duplicate version",
+			files: map[string]string{"001_a.sql": "", "001_b.sql": ""},
+		},
+		{
+			name:  "orphan down migration",
+			files: map[string]string{"001_a.sql": "", "002_b.down.sql": ""},
+		},
+		{
+			name:  "missing version prefix",
+			files: map[string]string{"initial.sql": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadMigrations(writeMigrations(t, tt.files)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestLoadMigrations_Embedded(t *testing.T) {
+	migrations, err := LoadMigrations(MigrationsFS(""))
+	if err != nil {
+		t.Fatalf("LoadMigrations() error = %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("expected embedded migrations")
+	}
+
+	onDisk, err := LoadMigrations(MigrationsFS("../../migrations"))
+	if err != nil {
+		t.Fatalf("LoadMigrations() error = %v", err)
+	}
+	if len(onDisk) != len(migrations) || onDisk[0].Checksum != migrations[0].Checksum {
+		t.Errorf("embedded migrations differ from the migrations directory")
+	}
+
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			t.Errorf("migration %s: expected contiguous version %d", migration.Name, i+1)
+		}
+		if migration.Down == "" {
+			t.Errorf("migration %s has no down migration", migration.Name)
+		}
+	}
+}
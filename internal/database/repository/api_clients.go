@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	stderrors "errors"
+
+	"github.com/leopoldhub/royal-api-personal/internal/errors"
+	"github.com/leopoldhub/royal-api-personal/internal/models"
+	"github.com/lib/pq"
+)
+
+// uniqueViolation is the PostgreSQL error code of a unique constraint violation
+const uniqueViolation = "23505"
+
+type PostgresAPIClientRepo struct {
+	db *sql.DB
+}
+
+var _ APIClientRepository = (*PostgresAPIClientRepo)(nil)
+
+func NewAPIClientRepository(db *sql.DB) APIClientRepository {
+	return &PostgresAPIClientRepo{db: db}
+}
+
+// Create stores a client with the hash of its token
+func (r *PostgresAPIClientRepo) Create(ctx context.Context, client *models.APIClient, tokenHash string) error {
+	query := `
+		INSERT INTO api_clients (name, token_hash, scopes, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		client.Name,
+		tokenHash,
+		pq.Array(client.Scopes),
+		client.ExpiresAt,
+	).Scan(&client.ID, &client.CreatedAt)
+	var pqErr *pq.Error
+	if stderrors.As(err, &pqErr) && pqErr.Code == uniqueViolation && pqErr.Constraint == "api_clients_name_key" {
+		return &errors.ValidationError{Field: "name", Message: "already used by another client"}
+	}
+	if err != nil {
+		return &errors.DBError{
+			Operation: "insert",
+			Table:     "api_clients",
+			Err:       err,
+		}
+	}
+
+	return nil
+}
+
+// GetByTokenHash returns the client owning the token hash, nil when unknown
+func (r *PostgresAPIClientRepo) GetByTokenHash(ctx context.Context, tokenHash string) (*models.APIClient, error) {
+	query := `
+		SELECT id, name, scopes, created_at, expires_at, revoked_at
+		FROM api_clients
+		WHERE token_hash = $1
+	`
+
+	client, err := scanAPIClient(r.db.QueryRowContext(ctx, query, tokenHash))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return client, err
+}
+
+// List returns every client, revoked ones included, by name
+func (r *PostgresAPIClientRepo) List(ctx context.Context) ([]*models.APIClient, error) {
+	query := `
+		SELECT id, name, scopes, created_at, expires_at, revoked_at
+		FROM api_clients
+		ORDER BY name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, &errors.DBError{
+			Operation: "query_all",
+			Table:     "api_clients",
+			Err:       err,
+		}
+	}
+	defer rows.Close()
+
+	clients := make([]*models.APIClient, 0)
+	for rows.Next() {
+		client, err := scanAPIClient(rows)
+		if err != nil {
+			return nil, err
+		}
+		clients = append(clients, client)
+	}
+
+	return clients, rows.Err()
+}
+
+// Revoke revokes the token of a client, false when the client is unknown or already revoked
+func (r *PostgresAPIClientRepo) Revoke(ctx context.Context, id int) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE api_clients SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL", id,
+	)
+	if err != nil {
+		return false, &errors.DBError{
+			Operation: "revoke",
+			Table:     "api_clients",
+			Err:       err,
+		}
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return false, &errors.DBError{
+			Operation: "revoke",
+			Table:     "api_clients",
+			Err:       err,
+		}
+	}
+
+	return updated > 0, nil
+}
+
+func scanAPIClient(row rowScanner) (*models.APIClient, error) {
+	var client models.APIClient
+	var expiresAt, revokedAt sql.NullTime
+
+	err := row.Scan(
+		&client.ID,
+		&client.Name,
+		pq.Array(&client.Scopes),
+		&client.CreatedAt,
+		&expiresAt,
+		&revokedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, &errors.DBError{
+			Operation: "scan_row",
+			Table:     "api_clients",
+			Err:       err,
+		}
+	}
+
+	if expiresAt.Valid {
+		client.ExpiresAt = &expiresAt.Time
+	}
+	if revokedAt.Valid {
+		client.RevokedAt = &revokedAt.Time
+	}
+
+	return &client, nil
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"time"
 
 	"github.com/leopoldhub/royal-api-personal/internal/errors"
 	"github.com/leopoldhub/royal-api-personal/internal/models"
+	"github.com/lib/pq"
 )
 
 type PostgresBattleRepo struct {
@@ -33,8 +35,10 @@ func (r *PostgresBattleRepo) Insert(ctx context.Context, battle *models.Battle)
 		INSERT INTO battles (
 			battle_time, player_tag, opponent_tag, game_mode,
 			player_crowns, opponent_crowns, deck_signature,
-			deck_cards, is_victory
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			deck_cards, is_victory, patch_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9,
+			(SELECT id FROM patches WHERE released_at <= $1 ORDER BY released_at DESC LIMIT 1)
+		)
 		ON CONFLICT (player_tag, battle_time) DO NOTHING
 	`
 
@@ -61,14 +65,18 @@ func (r *PostgresBattleRepo) Insert(ctx context.Context, battle *models.Battle)
 	return nil
 }
 
-func (r *PostgresBattleRepo) BatchInsert(ctx context.Context, battles []*models.Battle) error {
+// BatchInsert streams battles into a temporary staging table with COPY, then merges
+// them into battles in a single transaction. Duplicates (already stored or repeated
+// in the batch) are ignored and reported in the summary.
+func (r *PostgresBattleRepo) BatchInsert(ctx context.Context, battles []*models.Battle) (models.InsertSummary, error) {
+	var summary models.InsertSummary
 	if len(battles) == 0 {
-		return nil
+		return summary, nil
 	}
 
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return &errors.DBError{
+		return summary, &errors.DBError{
 			Operation: "begin_transaction",
 			Table:     "battles",
 			Err:       err,
@@ -76,30 +84,131 @@ func (r *PostgresBattleRepo) BatchInsert(ctx context.Context, battles []*models.
 	}
 	defer tx.Rollback()
 
-	stmt, err := tx.PrepareContext(ctx, `
-		INSERT INTO battles (
-			battle_time, player_tag, opponent_tag, game_mode,
-			player_crowns, opponent_crowns, deck_signature,
-			deck_cards, is_victory
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-		ON CONFLICT (player_tag, battle_time) DO NOTHING
+	summary, err = insertBatch(ctx, tx, battles)
+	if err != nil {
+		return summary, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return summary, &errors.DBError{
+			Operation: "commit",
+			Table:     "battles",
+			Err:       err,
+		}
+	}
+
+	return summary, nil
+}
+
+// ReplacePlayerRange deletes the battles of a player played between from and to
+// (inclusive) and inserts battles in their place, in a single transaction so that
+// a failed insert keeps the previous battles. It returns the number of deleted
+// battles and the insert summary.
+func (r *PostgresBattleRepo) ReplacePlayerRange(
+	ctx context.Context,
+	playerTag string,
+	from, to time.Time,
+	battles []*models.Battle,
+) (int64, models.InsertSummary, error) {
+	var summary models.InsertSummary
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, summary, &errors.DBError{
+			Operation: "begin_transaction",
+			Table:     "battles",
+			Err:       err,
+		}
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		DELETE FROM battles
+		WHERE player_tag = $1 AND battle_time BETWEEN $2 AND $3
+	`, playerTag, from, to)
+	if err != nil {
+		return 0, summary, &errors.DBError{
+			Operation: "delete_player_range",
+			Table:     "battles",
+			Err:       err,
+		}
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, summary, &errors.DBError{
+			Operation: "rows_affected",
+			Table:     "battles",
+			Err:       err,
+		}
+	}
+
+	if len(battles) > 0 {
+		summary, err = insertBatch(ctx, tx, battles)
+		if err != nil {
+			return 0, summary, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, summary, &errors.DBError{
+			Operation: "commit",
+			Table:     "battles",
+			Err:       err,
+		}
+	}
+
+	return deleted, summary, nil
+}
+
+// insertBatch streams battles into a staging table dropped at the end of tx with
+// COPY, then merges them into battles
+func insertBatch(ctx context.Context, tx *sql.Tx, battles []*models.Battle) (models.InsertSummary, error) {
+	var summary models.InsertSummary
+
+	_, err := tx.ExecContext(ctx, `
+		CREATE TEMP TABLE battles_staging (
+			battle_time TIMESTAMP NOT NULL,
+			player_tag VARCHAR(20) NOT NULL,
+			opponent_tag VARCHAR(20),
+			game_mode VARCHAR(50),
+			player_crowns INT,
+			opponent_crowns INT,
+			deck_signature VARCHAR(255) NOT NULL,
+			deck_cards JSONB NOT NULL,
+			is_victory BOOLEAN
+		) ON COMMIT DROP
 	`)
 	if err != nil {
-		return &errors.DBError{
-			Operation: "prepare_statement",
+		return summary, &errors.DBError{
+			Operation: "create_staging",
+			Table:     "battles",
+			Err:       err,
+		}
+	}
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("battles_staging",
+		"battle_time", "player_tag", "opponent_tag", "game_mode",
+		"player_crowns", "opponent_crowns", "deck_signature",
+		"deck_cards", "is_victory",
+	))
+	if err != nil {
+		return summary, &errors.DBError{
+			Operation: "prepare_copy",
 			Table:     "battles",
 			Err:       err,
 		}
 	}
 	defer stmt.Close()
 
-	const batchSize = 100
-	for i, battle := range battles {
+	for _, battle := range battles {
 		cardsJSON, err := json.Marshal(battle.DeckCards)
 		if err != nil {
+			summary.Skipped++
 			continue
 		}
 
+		// deck_cards is sent as text: COPY would encode []byte as bytea
 		_, err = stmt.ExecContext(ctx,
 			battle.BattleTime,
 			battle.PlayerTag,
@@ -108,48 +217,107 @@ func (r *PostgresBattleRepo) BatchInsert(ctx context.Context, battles []*models.
 			battle.PlayerCrowns,
 			battle.OpponentCrowns,
 			battle.DeckSignature,
-			cardsJSON,
+			string(cardsJSON),
 			battle.IsVictory,
 		)
 		if err != nil {
-			return &errors.DBError{
-				Operation: "exec_insert",
+			return summary, &errors.DBError{
+				Operation: "copy_row",
 				Table:     "battles",
 				Err:       err,
 			}
 		}
+	}
 
-		if (i+1)%batchSize == 0 {
-			if err := tx.Commit(); err != nil {
-				return &errors.DBError{
-					Operation: "commit_batch",
-					Table:     "battles",
-					Err:       err,
-				}
-			}
-			tx, _ = r.db.BeginTx(ctx, nil)
-			stmt, _ = tx.PrepareContext(ctx, `
-				INSERT INTO battles (
-					battle_time, player_tag, opponent_tag, game_mode,
-					player_crowns, opponent_crowns, deck_signature,
-					deck_cards, is_victory
-				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-				ON CONFLICT (player_tag, battle_time) DO NOTHING
-			`)
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return summary, &errors.DBError{
+			Operation: "copy_flush",
+			Table:     "battles",
+			Err:       err,
 		}
 	}
 
-	return tx.Commit()
+	// Battles of days without a partition, older than the retention period, are
+	// skipped: partitions are created ahead by EnsurePartitions, not here
+	var outside int
+	err = tx.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM battles_staging
+		WHERE to_regclass('battles_p' || to_char(battle_time, 'YYYYMMDD')) IS NULL
+	`).Scan(&outside)
+	if err != nil {
+		return summary, &errors.DBError{
+			Operation: "count_unpartitioned",
+			Table:     "battles",
+			Err:       err,
+		}
+	}
+	summary.Skipped += outside
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO battles (
+			battle_time, player_tag, opponent_tag, game_mode,
+			player_crowns, opponent_crowns, deck_signature,
+			deck_cards, is_victory, patch_id
+		)
+		SELECT DISTINCT ON (player_tag, battle_time)
+			battle_time, player_tag, opponent_tag, game_mode,
+			player_crowns, opponent_crowns, deck_signature,
+			deck_cards, is_victory,
+			(SELECT p.id FROM patches p WHERE p.released_at <= battle_time ORDER BY p.released_at DESC LIMIT 1)
+		FROM battles_staging
+		WHERE to_regclass('battles_p' || to_char(battle_time, 'YYYYMMDD')) IS NOT NULL
+		ORDER BY player_tag, battle_time
+		ON CONFLICT (player_tag, battle_time) DO NOTHING
+	`)
+	if err != nil {
+		return summary, &errors.DBError{
+			Operation: "merge_staging",
+			Table:     "battles",
+			Err:       err,
+		}
+	}
+
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return summary, &errors.DBError{
+			Operation: "rows_affected",
+			Table:     "battles",
+			Err:       err,
+		}
+	}
+
+	summary.Inserted = int(inserted)
+	summary.Duplicates = len(battles) - summary.Skipped - summary.Inserted
+
+	return summary, nil
+}
+
+// EnsurePartitions creates the missing daily partitions of battles from from to to
+// (inclusive) and returns the number created
+func (r *PostgresBattleRepo) EnsurePartitions(ctx context.Context, from, to time.Time) (int, error) {
+	var created int
+	err := r.db.QueryRowContext(ctx,
+		"SELECT ensure_battles_partitions($1::date, $2::date)", from.UTC(), to.UTC(),
+	).Scan(&created)
+	if err != nil {
+		return 0, &errors.DBError{
+			Operation: "create_partitions",
+			Table:     "battles",
+			Err:       err,
+		}
+	}
+	return created, nil
 }
 
+// DeleteOlderThan drops the daily partitions older than days, then deletes the
+// remaining old rows of the partition straddling the cutoff
 func (r *PostgresBattleRepo) DeleteOlderThan(ctx context.Context, days int) (int64, error) {
 	query := `
-		DELETE FROM battles 
-		WHERE battle_time < NOW() - INTERVAL '1 day' * $1
+		SELECT purge_battles_before((NOW() AT TIME ZONE 'UTC') - INTERVAL '1 day' * $1)
 	`
 
-	result, err := r.db.ExecContext(ctx, query, days)
-	if err != nil {
+	var deleted int64
+	if err := r.db.QueryRowContext(ctx, query, days).Scan(&deleted); err != nil {
 		return 0, &errors.DBError{
 			Operation: "delete_old",
 			Table:     "battles",
@@ -157,7 +325,7 @@ func (r *PostgresBattleRepo) DeleteOlderThan(ctx context.Context, days int) (int
 		}
 	}
 
-	return result.RowsAffected()
+	return deleted, nil
 }
 
 func (r *PostgresBattleRepo) Count(ctx context.Context) (int, error) {
@@ -176,7 +344,8 @@ func (r *PostgresBattleRepo) Count(ctx context.Context) (int, error) {
 func (r *PostgresBattleRepo) GetRecent(ctx context.Context, deckSignature string, limit int) ([]*models.Battle, error) {
 	query := `
 		SELECT id, battle_time, player_tag, opponent_tag, game_mode,
-			   player_crowns, opponent_crowns, deck_signature, deck_cards, is_victory
+			   player_crowns, opponent_crowns, deck_signature, deck_cards, is_victory,
+			   patch_id
 		FROM battles
 		WHERE deck_signature = $1
 		ORDER BY battle_time DESC
@@ -197,6 +366,7 @@ func (r *PostgresBattleRepo) GetRecent(ctx context.Context, deckSignature string
 	for rows.Next() {
 		var battle models.Battle
 		var cardsJSON []byte
+		var patchID sql.NullInt64
 
 		err := rows.Scan(
 			&battle.ID,
@@ -209,6 +379,7 @@ func (r *PostgresBattleRepo) GetRecent(ctx context.Context, deckSignature string
 			&battle.DeckSignature,
 			&cardsJSON,
 			&battle.IsVictory,
+			&patchID,
 		)
 		if err != nil {
 			return nil, &errors.DBError{
@@ -221,6 +392,10 @@ func (r *PostgresBattleRepo) GetRecent(ctx context.Context, deckSignature string
 		if err := json.Unmarshal(cardsJSON, &battle.DeckCards); err != nil {
 			continue
 		}
+		if patchID.Valid {
+			id := int(patchID.Int64)
+			battle.PatchID = &id
+		}
 
 		battles = append(battles, &battle)
 	}
@@ -0,0 +1,302 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"time"
+
+	"github.com/leopoldhub/royal-api-personal/internal/errors"
+	"github.com/leopoldhub/royal-api-personal/internal/models"
+	"github.com/lib/pq"
+)
+
+// collectionLockKey is the advisory lock key held during a collection run
+const collectionLockKey = 725369
+
+// unlockTimeout bounds the release of the collection lock
+const unlockTimeout = 5 * time.Second
+
+type PostgresCollectionStatsRepo struct {
+	db *sql.DB
+}
+
+var _ CollectionStatsRepository = (*PostgresCollectionStatsRepo)(nil)
+
+func NewCollectionStatsRepository(db *sql.DB) CollectionStatsRepository {
+	return &PostgresCollectionStatsRepo{db: db}
+}
+
+func (r *PostgresCollectionStatsRepo) Start(ctx context.Context, stats *models.CollectionStats) error {
+	query := `
+		INSERT INTO collection_stats (started_at, status)
+		VALUES ($1, $2)
+		RETURNING id
+	`
+
+	stats.Status = models.CollectionRunning
+	err := r.db.QueryRowContext(ctx, query, stats.StartedAt, stats.Status).Scan(&stats.ID)
+	if err != nil {
+		return &errors.DBError{
+			Operation: "insert",
+			Table:     "collection_stats",
+			Err:       err,
+		}
+	}
+
+	return nil
+}
+
+func (r *PostgresCollectionStatsRepo) Update(ctx context.Context, stats *models.CollectionStats) error {
+	query := `
+		UPDATE collection_stats SET
+			completed_at = $2,
+			players_processed = $3,
+			battles_collected = $4,
+			battles_stored = $5,
+			players_checked = $6,
+			players_with_gaps = $7,
+			errors = $8,
+			status = $9,
+			error_message = $10,
+			battles_duplicate = $11,
+			battles_skipped = $12
+		WHERE id = $1
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		stats.ID,
+		nullTime(stats.CompletedAt),
+		stats.PlayersProcessed,
+		stats.BattlesCollected,
+		stats.BattlesStored,
+		stats.PlayersChecked,
+		stats.PlayersWithGaps,
+		stats.Errors,
+		stats.Status,
+		nullString(stats.ErrorMessage),
+		stats.BattlesDuplicate,
+		stats.BattlesSkipped,
+	)
+	if err != nil {
+		return &errors.DBError{
+			Operation: "update",
+			Table:     "collection_stats",
+			Err:       err,
+		}
+	}
+
+	return nil
+}
+
+func (r *PostgresCollectionStatsRepo) GetLatest(ctx context.Context) (*models.CollectionStats, error) {
+	runs, _, err := r.GetRecent(ctx, models.Page{Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return nil, nil
+	}
+	return runs[0], nil
+}
+
+// GetRecent returns a page of completed runs, most recent first, with the number of completed runs
+func (r *PostgresCollectionStatsRepo) GetRecent(ctx context.Context, page models.Page) ([]*models.CollectionStats, int, error) {
+	var total int
+	err := r.db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM collection_stats WHERE status = $1", models.CollectionCompleted,
+	).Scan(&total)
+	if err != nil {
+		return nil, 0, &errors.DBError{
+			Operation: "count_recent",
+			Table:     "collection_stats",
+			Err:       err,
+		}
+	}
+
+	query := `
+		SELECT id, started_at, completed_at, players_processed, battles_collected,
+			   battles_stored, battles_duplicate, battles_skipped,
+			   players_checked, players_with_gaps, errors,
+			   status, error_message
+		FROM collection_stats
+		WHERE status = $1
+		ORDER BY started_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, models.CollectionCompleted, page.Limit, page.Offset)
+	if err != nil {
+		return nil, 0, &errors.DBError{
+			Operation: "query_recent",
+			Table:     "collection_stats",
+			Err:       err,
+		}
+	}
+	defer rows.Close()
+
+	runs, err := scanCollectionStats(rows)
+	return runs, total, err
+}
+
+func (r *PostgresCollectionStatsRepo) GetRunning(ctx context.Context) (*models.CollectionStats, error) {
+	query := `
+		SELECT id, started_at, completed_at, players_processed, battles_collected,
+			   battles_stored, battles_duplicate, battles_skipped,
+			   players_checked, players_with_gaps, errors,
+			   status, error_message
+		FROM collection_stats
+		WHERE status = $1
+		ORDER BY started_at DESC
+		LIMIT 1
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, models.CollectionRunning)
+	if err != nil {
+		return nil, &errors.DBError{
+			Operation: "query_running",
+			Table:     "collection_stats",
+			Err:       err,
+		}
+	}
+	defer rows.Close()
+
+	runs, err := scanCollectionStats(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return nil, nil
+	}
+	return runs[0], nil
+}
+
+func (r *PostgresCollectionStatsRepo) Checkpoint(ctx context.Context, collectionID int, playerTags []string) error {
+	if len(playerTags) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO collection_checkpoints (collection_id, player_tag)
+		SELECT $1, unnest($2::varchar[])
+		ON CONFLICT (collection_id, player_tag) DO NOTHING
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, collectionID, pq.Array(playerTags)); err != nil {
+		return &errors.DBError{
+			Operation: "insert",
+			Table:     "collection_checkpoints",
+			Err:       err,
+		}
+	}
+
+	return nil
+}
+
+func (r *PostgresCollectionStatsRepo) GetCheckpoint(ctx context.Context, collectionID int) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT player_tag FROM collection_checkpoints WHERE collection_id = $1",
+		collectionID,
+	)
+	if err != nil {
+		return nil, &errors.DBError{
+			Operation: "query",
+			Table:     "collection_checkpoints",
+			Err:       err,
+		}
+	}
+	defer rows.Close()
+
+	var tags []string
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, &errors.DBError{
+				Operation: "scan_row",
+				Table:     "collection_checkpoints",
+				Err:       err,
+			}
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
+
+func scanCollectionStats(rows *sql.Rows) ([]*models.CollectionStats, error) {
+	var runs []*models.CollectionStats
+	for rows.Next() {
+		var stats models.CollectionStats
+		var completedAt sql.NullTime
+		var errorMessage sql.NullString
+
+		err := rows.Scan(
+			&stats.ID,
+			&stats.StartedAt,
+			&completedAt,
+			&stats.PlayersProcessed,
+			&stats.BattlesCollected,
+			&stats.BattlesStored,
+			&stats.BattlesDuplicate,
+			&stats.BattlesSkipped,
+			&stats.PlayersChecked,
+			&stats.PlayersWithGaps,
+			&stats.Errors,
+			&stats.Status,
+			&errorMessage,
+		)
+		if err != nil {
+			return nil, &errors.DBError{
+				Operation: "scan_row",
+				Table:     "collection_stats",
+				Err:       err,
+			}
+		}
+
+		stats.CompletedAt = completedAt.Time
+		stats.ErrorMessage = errorMessage.String
+		runs = append(runs, &stats)
+	}
+
+	return runs, rows.Err()
+}
+
+// TryLock takes a session advisory lock on a dedicated connection, so that the
+// lock is released by Postgres when the process dies
+func (r *PostgresCollectionStatsRepo) TryLock(ctx context.Context) (func(), bool, error) {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return nil, false, &errors.DBError{
+			Operation: "lock",
+			Table:     "collection_stats",
+			Err:       err,
+		}
+	}
+
+	var locked bool
+	err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", collectionLockKey).Scan(&locked)
+	if err != nil || !locked {
+		conn.Close()
+		if err != nil {
+			return nil, false, &errors.DBError{
+				Operation: "lock",
+				Table:     "collection_stats",
+				Err:       err,
+			}
+		}
+		return nil, false, nil
+	}
+
+	release := func() {
+		// The run context may be canceled, the lock is released regardless
+		ctx, cancel := context.WithTimeout(context.Background(), unlockTimeout)
+		defer cancel()
+
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", collectionLockKey); err != nil {
+			// Discard the connection so that its session, and the lock, ends
+			conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+		conn.Close()
+	}
+	return release, true, nil
+}
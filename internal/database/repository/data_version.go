@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/leopoldhub/royal-api-personal/internal/errors"
+	"github.com/leopoldhub/royal-api-personal/internal/models"
+)
+
+type PostgresDataVersionRepo struct {
+	db *sql.DB
+}
+
+var _ DataVersionRepository = (*PostgresDataVersionRepo)(nil)
+
+func NewDataVersionRepository(db *sql.DB) DataVersionRepository {
+	return &PostgresDataVersionRepo{db: db}
+}
+
+// Get returns the current data version, bumped by triggers on every change
+func (r *PostgresDataVersionRepo) Get(ctx context.Context) (*models.DataVersion, error) {
+	var version models.DataVersion
+	err := r.db.QueryRowContext(ctx,
+		"SELECT version, updated_at FROM data_version",
+	).Scan(&version.Version, &version.UpdatedAt)
+	if err != nil {
+		return nil, &errors.DBError{
+			Operation: "get",
+			Table:     "data_version",
+			Err:       err,
+		}
+	}
+	return &version, nil
+}
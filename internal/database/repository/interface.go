@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/leopoldhub/royal-api-personal/internal/models"
 )
@@ -9,8 +10,10 @@ import (
 // BattleRepository manages battle data persistence
 type BattleRepository interface {
 	Insert(ctx context.Context, battle *models.Battle) error
-	BatchInsert(ctx context.Context, battles []*models.Battle) error
+	BatchInsert(ctx context.Context, battles []*models.Battle) (models.InsertSummary, error)
+	EnsurePartitions(ctx context.Context, from, to time.Time) (int, error)
 	DeleteOlderThan(ctx context.Context, days int) (int64, error)
+	ReplacePlayerRange(ctx context.Context, playerTag string, from, to time.Time, battles []*models.Battle) (int64, models.InsertSummary, error)
 	Count(ctx context.Context) (int, error)
 	GetRecent(ctx context.Context, deckSignature string, limit int) ([]*models.Battle, error)
 }
@@ -18,7 +21,78 @@ type BattleRepository interface {
 // MetaDeckRepository manages aggregated deck statistics
 type MetaDeckRepository interface {
 	Recalculate(ctx context.Context) error
-	GetTop(ctx context.Context, limit int, sortBy string, minGames int) ([]*models.Deck, error)
+	GetTop(ctx context.Context, page models.Page, sortBy string, minGames int) ([]*models.Deck, int, error)
+	GetTopByPatch(ctx context.Context, patchID int, page models.Page, sortBy string, minGames int) ([]*models.Deck, int, error)
 	GetBySignature(ctx context.Context, signature string) (*models.Deck, error)
 	Count(ctx context.Context) (int, error)
+	LastUpdated(ctx context.Context) (time.Time, error)
+	GetWindowStats(ctx context.Context, baselineFrom, recentFrom time.Time, minRecentGames int) ([]*models.DeckWindowStats, error)
+}
+
+// PlayerScheduleRepository manages adaptive polling state per player
+type PlayerScheduleRepository interface {
+	GetAll(ctx context.Context) (map[string]*models.PlayerSchedule, error)
+	Upsert(ctx context.Context, schedules []*models.PlayerSchedule) error
+}
+
+// CollectionStatsRepository records collection runs for monitoring
+type CollectionStatsRepository interface {
+	Start(ctx context.Context, stats *models.CollectionStats) error
+	Update(ctx context.Context, stats *models.CollectionStats) error
+	GetLatest(ctx context.Context) (*models.CollectionStats, error)
+	GetRecent(ctx context.Context, page models.Page) ([]*models.CollectionStats, int, error)
+	GetRunning(ctx context.Context) (*models.CollectionStats, error)
+	Checkpoint(ctx context.Context, collectionID int, playerTags []string) error
+	GetCheckpoint(ctx context.Context, collectionID int) ([]string, error)
+	// TryLock takes the lock serializing collection runs across processes, ok
+	// being false when another run holds it. release frees a taken lock.
+	TryLock(ctx context.Context) (release func(), ok bool, err error)
+}
+
+// RejectedBattleRepository stores raw battles rejected by the parser
+type RejectedBattleRepository interface {
+	Insert(ctx context.Context, rejected []*models.RejectedBattle) error
+	CountByReason(ctx context.Context, days int) (map[string]int, error)
+}
+
+// RawBattlelogRepository archives raw battlelogs for re-parsing
+type RawBattlelogRepository interface {
+	Insert(ctx context.Context, battlelog *models.RawBattlelog) error
+	List(ctx context.Context, afterID int64, limit int) ([]*models.RawBattlelog, error)
+	DeleteOlderThan(ctx context.Context, days int) (int64, error)
+}
+
+// History intervals supported by MetaHistoryRepository
+const (
+	IntervalDay  = "day"
+	IntervalWeek = "week"
+)
+
+// MetaHistoryRepository rolls battles up into permanent daily aggregates
+type MetaHistoryRepository interface {
+	Rollup(ctx context.Context, retentionDays int) (int64, error)
+	Rebuild(ctx context.Context, from, to time.Time, retentionDays int) (int64, error)
+	GetDeckHistory(ctx context.Context, signature string, interval string, days int) ([]*models.DeckHistoryPoint, error)
+}
+
+// PatchRepository manages balance patches and compares the meta across them
+type PatchRepository interface {
+	Create(ctx context.Context, patch *models.Patch) error
+	List(ctx context.Context, page models.Page) ([]*models.Patch, int, error)
+	GetByID(ctx context.Context, id int) (*models.Patch, error)
+	Delete(ctx context.Context, id int) (bool, error)
+	Compare(ctx context.Context, patch *models.Patch, minGames int) (*models.PatchComparison, error)
+}
+
+// APIClientRepository manages the API clients, tokens being stored as hashes
+type APIClientRepository interface {
+	Create(ctx context.Context, client *models.APIClient, tokenHash string) error
+	GetByTokenHash(ctx context.Context, tokenHash string) (*models.APIClient, error)
+	List(ctx context.Context) ([]*models.APIClient, error)
+	Revoke(ctx context.Context, id int) (bool, error)
+}
+
+// DataVersionRepository reads the version of the data served by the read endpoints
+type DataVersionRepository interface {
+	Get(ctx context.Context) (*models.DataVersion, error)
 }
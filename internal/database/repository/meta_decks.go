@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/leopoldhub/royal-api-personal/internal/errors"
 	"github.com/leopoldhub/royal-api-personal/internal/models"
@@ -68,15 +69,19 @@ func (r *PostgresMetaRepo) Recalculate(ctx context.Context) error {
 	return tx.Commit()
 }
 
-func (r *PostgresMetaRepo) GetTop(ctx context.Context, limit int, sortBy string, minGames int) ([]*models.Deck, error) {
-	var orderColumn string
-	switch sortBy {
-	case "frequency":
-		orderColumn = "total_games"
-	case "win_rate":
-		orderColumn = "win_rate"
-	default:
-		orderColumn = "win_rate"
+// GetTop returns a page of meta decks played at least minGames times, with the
+// number of decks matching the filter
+func (r *PostgresMetaRepo) GetTop(ctx context.Context, page models.Page, sortBy string, minGames int) ([]*models.Deck, int, error) {
+	var total int
+	err := r.db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM meta_decks WHERE total_games >= $1", minGames,
+	).Scan(&total)
+	if err != nil {
+		return nil, 0, &errors.DBError{
+			Operation: "count_top",
+			Table:     "meta_decks",
+			Err:       err,
+		}
 	}
 
 	query := fmt.Sprintf(`
@@ -84,13 +89,13 @@ func (r *PostgresMetaRepo) GetTop(ctx context.Context, limit int, sortBy string,
 			   win_rate, first_seen, last_seen, updated_at
 		FROM meta_decks
 		WHERE total_games >= $1
-		ORDER BY %s DESC
-		LIMIT $2
-	`, orderColumn)
+		ORDER BY %s DESC, deck_signature
+		LIMIT $2 OFFSET $3
+	`, orderColumn(sortBy))
 
-	rows, err := r.db.QueryContext(ctx, query, minGames, limit)
+	rows, err := r.db.QueryContext(ctx, query, minGames, page.Limit, page.Offset)
 	if err != nil {
-		return nil, &errors.DBError{
+		return nil, 0, &errors.DBError{
 			Operation: "query_top",
 			Table:     "meta_decks",
 			Err:       err,
@@ -98,6 +103,76 @@ func (r *PostgresMetaRepo) GetTop(ctx context.Context, limit int, sortBy string,
 	}
 	defer rows.Close()
 
+	decks, err := scanDecks(rows, "meta_decks")
+	return decks, total, err
+}
+
+// patchDecks aggregates the battles played during a patch, meta_decks only
+// holds statistics over every retained battle
+const patchDecks = `
+	SELECT
+		deck_signature,
+		(array_agg(deck_cards ORDER BY battle_time DESC))[1] AS cards,
+		COUNT(*) AS total_games,
+		SUM(CASE WHEN is_victory THEN 1 ELSE 0 END) AS wins,
+		SUM(CASE WHEN NOT is_victory THEN 1 ELSE 0 END) AS losses,
+		ROUND((SUM(CASE WHEN is_victory THEN 1 ELSE 0 END)::numeric / COUNT(*)::numeric) * 100, 2) AS win_rate,
+		MIN(battle_time) AS first_seen,
+		MAX(battle_time) AS last_seen,
+		NOW()::timestamp AS updated_at
+	FROM battles
+	WHERE patch_id = $1
+	GROUP BY deck_signature
+`
+
+// GetTopByPatch returns a page of the decks played at least minGames times during
+// a patch, with the number of decks matching the filter
+func (r *PostgresMetaRepo) GetTopByPatch(ctx context.Context, patchID int, page models.Page, sortBy string, minGames int) ([]*models.Deck, int, error) {
+	var total int
+	err := r.db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM ("+patchDecks+") decks WHERE total_games >= $2", patchID, minGames,
+	).Scan(&total)
+	if err != nil {
+		return nil, 0, &errors.DBError{
+			Operation: "count_top_by_patch",
+			Table:     "battles",
+			Err:       err,
+		}
+	}
+
+	query := fmt.Sprintf(`
+		SELECT * FROM (%s) decks
+		WHERE total_games >= $2
+		ORDER BY %s DESC, deck_signature
+		LIMIT $3 OFFSET $4
+	`, patchDecks, orderColumn(sortBy))
+
+	rows, err := r.db.QueryContext(ctx, query, patchID, minGames, page.Limit, page.Offset)
+	if err != nil {
+		return nil, 0, &errors.DBError{
+			Operation: "query_top_by_patch",
+			Table:     "battles",
+			Err:       err,
+		}
+	}
+	defer rows.Close()
+
+	decks, err := scanDecks(rows, "battles")
+	return decks, total, err
+}
+
+// orderColumn maps the sort parameter of the meta endpoints to a column
+func orderColumn(sortBy string) string {
+	switch sortBy {
+	case "frequency":
+		return "total_games"
+	default:
+		return "win_rate"
+	}
+}
+
+// scanDecks reads deck rows with their statistics
+func scanDecks(rows *sql.Rows, table string) ([]*models.Deck, error) {
 	var decks []*models.Deck
 	for rows.Next() {
 		var deck models.Deck
@@ -118,19 +193,23 @@ func (r *PostgresMetaRepo) GetTop(ctx context.Context, limit int, sortBy string,
 		if err != nil {
 			return nil, &errors.DBError{
 				Operation: "scan_row",
-				Table:     "meta_decks",
+				Table:     table,
 				Err:       err,
 			}
 		}
 
 		if err := json.Unmarshal(cardsJSON, &deck.Cards); err != nil {
-			continue
+			return nil, &errors.DBError{
+				Operation: "unmarshal_cards",
+				Table:     table,
+				Err:       err,
+			}
 		}
 
 		decks = append(decks, &deck)
 	}
 
-	return decks, nil
+	return decks, rows.Err()
 }
 
 func (r *PostgresMetaRepo) GetBySignature(ctx context.Context, signature string) (*models.Deck, error) {
@@ -179,6 +258,20 @@ func (r *PostgresMetaRepo) GetBySignature(ctx context.Context, signature string)
 	return &deck, nil
 }
 
+// LastUpdated returns when meta_decks was last recalculated, zero when empty
+func (r *PostgresMetaRepo) LastUpdated(ctx context.Context) (time.Time, error) {
+	var updated sql.NullTime
+	err := r.db.QueryRowContext(ctx, "SELECT MAX(updated_at) FROM meta_decks").Scan(&updated)
+	if err != nil {
+		return time.Time{}, &errors.DBError{
+			Operation: "last_updated",
+			Table:     "meta_decks",
+			Err:       err,
+		}
+	}
+	return updated.Time, nil
+}
+
 func (r *PostgresMetaRepo) Count(ctx context.Context) (int, error) {
 	var count int
 	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM meta_decks").Scan(&count)
@@ -191,3 +284,88 @@ func (r *PostgresMetaRepo) Count(ctx context.Context) (int, error) {
 	}
 	return count, nil
 }
+
+// GetWindowStats returns the games of each deck played at least minRecentGames times
+// since recentFrom, with its games in the baseline window [baselineFrom, recentFrom).
+// A deck is first seen on its first day in the permanent history, or on its first
+// battle still retained when it has not been rolled up yet.
+func (r *PostgresMetaRepo) GetWindowStats(
+	ctx context.Context,
+	baselineFrom, recentFrom time.Time,
+	minRecentGames int,
+) ([]*models.DeckWindowStats, error) {
+	query := `
+		WITH per_deck AS (
+			SELECT
+				deck_signature,
+				(array_agg(deck_cards ORDER BY battle_time DESC))[1] AS cards,
+				COUNT(*) FILTER (WHERE battle_time >= $2) AS recent_games,
+				COUNT(*) FILTER (WHERE battle_time >= $2 AND is_victory) AS recent_wins,
+				COUNT(*) FILTER (WHERE battle_time < $2) AS baseline_games,
+				COUNT(*) FILTER (WHERE battle_time < $2 AND is_victory) AS baseline_wins
+			FROM battles
+			WHERE battle_time >= $1
+			GROUP BY deck_signature
+		),
+		totals AS (
+			SELECT
+				COALESCE(SUM(recent_games), 0) AS recent_total,
+				COALESCE(SUM(baseline_games), 0) AS baseline_total
+			FROM per_deck
+		)
+		SELECT
+			d.deck_signature, d.cards,
+			d.recent_games, d.recent_wins, d.baseline_games, d.baseline_wins,
+			t.recent_total, t.baseline_total,
+			LEAST(
+				(SELECT MIN(h.day)::timestamp FROM deck_daily_stats h WHERE h.deck_signature = d.deck_signature),
+				(SELECT MIN(b.battle_time) FROM battles b WHERE b.deck_signature = d.deck_signature)
+			)
+		FROM per_deck d
+		CROSS JOIN totals t
+		WHERE d.recent_games >= $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, baselineFrom, recentFrom, minRecentGames)
+	if err != nil {
+		return nil, &errors.DBError{
+			Operation: "query_window_stats",
+			Table:     "battles",
+			Err:       err,
+		}
+	}
+	defer rows.Close()
+
+	var stats []*models.DeckWindowStats
+	for rows.Next() {
+		var deck models.DeckWindowStats
+		var cardsJSON []byte
+
+		err := rows.Scan(
+			&deck.Signature,
+			&cardsJSON,
+			&deck.RecentGames,
+			&deck.RecentWins,
+			&deck.BaselineGames,
+			&deck.BaselineWins,
+			&deck.RecentTotal,
+			&deck.BaselineTotal,
+			&deck.FirstSeen,
+		)
+		if err != nil {
+			return nil, &errors.DBError{
+				Operation: "scan_row",
+				Table:     "battles",
+				Err:       err,
+			}
+		}
+
+		if err := json.Unmarshal(cardsJSON, &deck.Cards); err != nil {
+			continue
+		}
+
+		stats = append(stats, &deck)
+	}
+
+	return stats, rows.Err()
+}
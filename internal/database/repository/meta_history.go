@@ -0,0 +1,286 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/leopoldhub/royal-api-personal/internal/errors"
+	"github.com/leopoldhub/royal-api-personal/internal/models"
+)
+
+type PostgresMetaHistoryRepo struct {
+	db *sql.DB
+}
+
+var _ MetaHistoryRepository = (*PostgresMetaHistoryRepo)(nil)
+
+func NewMetaHistoryRepository(db *sql.DB) MetaHistoryRepository {
+	return &PostgresMetaHistoryRepo{db: db}
+}
+
+// rollupStart returns the start of the first day to roll up: the day after the
+// last rolled-up day, so that days left over by a pause of the collector are
+// not purged without their aggregates, or the first complete day whose battles
+// are all still retained when it is older. The day straddling the retention
+// cutoff is partially purged, so its aggregates keep the values of the last
+// rollup made while it was complete. The end is the start of the current day.
+// Days are UTC, like the battle times.
+const rollupStart = `
+	SELECT
+		LEAST(
+			date_trunc('day', (NOW() AT TIME ZONE 'UTC') - INTERVAL '1 day' * $1) + INTERVAL '1 day',
+			COALESCE((SELECT MAX(day) + 1 FROM deck_daily_stats)::timestamp, NOW() AT TIME ZONE 'UTC')
+		),
+		date_trunc('day', NOW() AT TIME ZONE 'UTC')
+`
+
+// rebuildRange clips the days from $1 to $2 (inclusive) to the rolled-up days
+// whose battles are all still retained, returning their start and end. Battle
+// times are UTC without time zone.
+const rebuildRange = `
+	SELECT
+		GREATEST(
+			date_trunc('day', $1::timestamp),
+			date_trunc('day', (NOW() AT TIME ZONE 'UTC') - INTERVAL '1 day' * $3) + INTERVAL '1 day'
+		),
+		LEAST(
+			date_trunc('day', $2::timestamp) + INTERVAL '1 day',
+			COALESCE((SELECT MAX(day) + 1 FROM deck_daily_stats)::timestamp, '-infinity')
+		)
+`
+
+// rollupWindow selects the battles from $1 to $2 (exclusive)
+const rollupWindow = `
+	battle_time >= $1
+	AND battle_time < $2
+`
+
+// Rollup recomputes the daily deck and card aggregates of every complete day
+// newer than the last rolled-up day or within retentionDays, and returns the
+// number of deck rows written
+func (r *PostgresMetaHistoryRepo) Rollup(ctx context.Context, retentionDays int) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, &errors.DBError{
+			Operation: "begin_transaction",
+			Table:     "deck_daily_stats",
+			Err:       err,
+		}
+	}
+	defer tx.Rollback()
+
+	var start, end time.Time
+	if err := tx.QueryRowContext(ctx, rollupStart, retentionDays).Scan(&start, &end); err != nil {
+		return 0, &errors.DBError{
+			Operation: "query_rollup_start",
+			Table:     "deck_daily_stats",
+			Err:       err,
+		}
+	}
+
+	written, err := rollupDays(ctx, tx, start, end)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, &errors.DBError{
+			Operation: "commit",
+			Table:     "deck_daily_stats",
+			Err:       err,
+		}
+	}
+
+	return written, nil
+}
+
+// Rebuild recomputes the aggregates of the rolled-up days from from to to whose
+// battles are all still retained, after these battles were replaced. Rows of
+// decks and cards no longer played on these days are removed. It returns the
+// number of deck rows written.
+func (r *PostgresMetaHistoryRepo) Rebuild(ctx context.Context, from, to time.Time, retentionDays int) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, &errors.DBError{
+			Operation: "begin_transaction",
+			Table:     "deck_daily_stats",
+			Err:       err,
+		}
+	}
+	defer tx.Rollback()
+
+	var start, end time.Time
+	err = tx.QueryRowContext(ctx, rebuildRange, from.UTC(), to.UTC(), retentionDays).Scan(&start, &end)
+	if err != nil {
+		return 0, &errors.DBError{
+			Operation: "query_rebuild_range",
+			Table:     "deck_daily_stats",
+			Err:       err,
+		}
+	}
+	if !start.Before(end) {
+		return 0, nil
+	}
+
+	for _, table := range []string{"deck_daily_stats", "card_daily_stats"} {
+		_, err := tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE day >= $1 AND day < $2", start, end)
+		if err != nil {
+			return 0, &errors.DBError{
+				Operation: "delete_days",
+				Table:     table,
+				Err:       err,
+			}
+		}
+	}
+
+	written, err := rollupDays(ctx, tx, start, end)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, &errors.DBError{
+			Operation: "commit",
+			Table:     "deck_daily_stats",
+			Err:       err,
+		}
+	}
+
+	return written, nil
+}
+
+// rollupDays upserts the deck and card aggregates of the battles from start to
+// end and returns the number of deck rows written
+func rollupDays(ctx context.Context, tx *sql.Tx, start, end time.Time) (int64, error) {
+	decksQuery := `
+		INSERT INTO deck_daily_stats (day, deck_signature, cards, games, wins, losses)
+		SELECT
+			battle_time::date AS day,
+			deck_signature,
+			(array_agg(deck_cards ORDER BY battle_time DESC))[1] AS cards,
+			COUNT(*) AS games,
+			SUM(CASE WHEN is_victory THEN 1 ELSE 0 END) AS wins,
+			SUM(CASE WHEN NOT is_victory THEN 1 ELSE 0 END) AS losses
+		FROM battles
+		WHERE ` + rollupWindow + `
+		GROUP BY battle_time::date, deck_signature
+		ON CONFLICT (day, deck_signature) DO UPDATE SET
+			cards = EXCLUDED.cards,
+			games = EXCLUDED.games,
+			wins = EXCLUDED.wins,
+			losses = EXCLUDED.losses,
+			updated_at = NOW()
+	`
+
+	result, err := tx.ExecContext(ctx, decksQuery, start, end)
+	if err != nil {
+		return 0, &errors.DBError{
+			Operation: "rollup",
+			Table:     "deck_daily_stats",
+			Err:       err,
+		}
+	}
+
+	cardsQuery := `
+		INSERT INTO card_daily_stats (day, card_id, card_name, games, wins)
+		SELECT
+			b.battle_time::date AS day,
+			card->>'id' AS card_id,
+			MAX(card->>'name') AS card_name,
+			COUNT(*) AS games,
+			SUM(CASE WHEN b.is_victory THEN 1 ELSE 0 END) AS wins
+		FROM battles b
+		CROSS JOIN LATERAL jsonb_array_elements(b.deck_cards) AS card
+		WHERE ` + rollupWindow + `
+		GROUP BY b.battle_time::date, card->>'id'
+		ON CONFLICT (day, card_id) DO UPDATE SET
+			card_name = EXCLUDED.card_name,
+			games = EXCLUDED.games,
+			wins = EXCLUDED.wins,
+			updated_at = NOW()
+	`
+
+	if _, err := tx.ExecContext(ctx, cardsQuery, start, end); err != nil {
+		return 0, &errors.DBError{
+			Operation: "rollup",
+			Table:     "card_daily_stats",
+			Err:       err,
+		}
+	}
+
+	return result.RowsAffected()
+}
+
+// GetDeckHistory returns the statistics of a deck per interval over the last days,
+// oldest first. Usage share is relative to every game played in the period.
+func (r *PostgresMetaHistoryRepo) GetDeckHistory(
+	ctx context.Context,
+	signature string,
+	interval string,
+	days int,
+) ([]*models.DeckHistoryPoint, error) {
+	if interval != IntervalDay && interval != IntervalWeek {
+		return nil, &errors.DBError{
+			Operation: "query_deck_history",
+			Table:     "deck_daily_stats",
+			Err:       fmt.Errorf("unsupported interval %q", interval),
+		}
+	}
+
+	query := `
+		WITH totals AS (
+			SELECT date_trunc($2, day) AS period, SUM(games) AS games
+			FROM deck_daily_stats
+			WHERE day >= (NOW() AT TIME ZONE 'UTC')::date - $3::int
+			GROUP BY 1
+		)
+		SELECT
+			date_trunc($2, d.day) AS period,
+			SUM(d.games) AS games,
+			SUM(d.wins) AS wins,
+			ROUND(SUM(d.wins)::numeric / NULLIF(SUM(d.games), 0)::numeric * 100, 2) AS win_rate,
+			ROUND(SUM(d.games)::numeric / NULLIF(MAX(t.games), 0)::numeric * 100, 2) AS usage_share
+		FROM deck_daily_stats d
+		JOIN totals t ON t.period = date_trunc($2, d.day)
+		WHERE d.deck_signature = $1 AND d.day >= (NOW() AT TIME ZONE 'UTC')::date - $3::int
+		GROUP BY 1
+		ORDER BY 1
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, signature, interval, days)
+	if err != nil {
+		return nil, &errors.DBError{
+			Operation: "query_deck_history",
+			Table:     "deck_daily_stats",
+			Err:       err,
+		}
+	}
+	defer rows.Close()
+
+	points := make([]*models.DeckHistoryPoint, 0)
+	for rows.Next() {
+		var point models.DeckHistoryPoint
+		var winRate, usageShare sql.NullFloat64
+		err := rows.Scan(
+			&point.Period,
+			&point.Games,
+			&point.Wins,
+			&winRate,
+			&usageShare,
+		)
+		if err != nil {
+			return nil, &errors.DBError{
+				Operation: "scan_row",
+				Table:     "deck_daily_stats",
+				Err:       err,
+			}
+		}
+		point.WinRate = winRate.Float64
+		point.UsageShare = usageShare.Float64
+		points = append(points, &point)
+	}
+
+	return points, rows.Err()
+}
@@ -0,0 +1,466 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/leopoldhub/royal-api-personal/internal/errors"
+	"github.com/leopoldhub/royal-api-personal/internal/models"
+	"github.com/lib/pq"
+)
+
+type PostgresPatchRepo struct {
+	db *sql.DB
+}
+
+var _ PatchRepository = (*PostgresPatchRepo)(nil)
+
+func NewPatchRepository(db *sql.DB) PatchRepository {
+	return &PostgresPatchRepo{db: db}
+}
+
+// Create stores a patch and tags the battles played from its release until the next patch
+func (r *PostgresPatchRepo) Create(ctx context.Context, patch *models.Patch) error {
+	cardsJSON, err := json.Marshal(patch.AffectedCards)
+	if err != nil {
+		return &errors.DBError{
+			Operation: "marshal_cards",
+			Table:     "patches",
+			Err:       err,
+		}
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return &errors.DBError{
+			Operation: "begin_transaction",
+			Table:     "patches",
+			Err:       err,
+		}
+	}
+	defer tx.Rollback()
+
+	if err := checkKnownCards(ctx, tx, patch.AffectedCards); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO patches (released_at, description, affected_cards)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		patch.ReleasedAt,
+		nullString(patch.Description),
+		string(cardsJSON),
+	).Scan(&patch.ID, &patch.CreatedAt)
+	var pqErr *pq.Error
+	if stderrors.As(err, &pqErr) && pqErr.Code == uniqueViolation && pqErr.Constraint == "patches_released_at_key" {
+		return &errors.ValidationError{Field: "released_at", Message: "already used by another patch"}
+	}
+	if err != nil {
+		return &errors.DBError{
+			Operation: "insert",
+			Table:     "patches",
+			Err:       err,
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE battles SET patch_id = $1
+		WHERE battle_time >= $2
+		  AND battle_time < COALESCE(
+			(SELECT MIN(released_at) FROM patches WHERE released_at > $2),
+			'infinity'::timestamp
+		  )
+	`, patch.ID, patch.ReleasedAt)
+	if err != nil {
+		return &errors.DBError{
+			Operation: "tag_battles",
+			Table:     "battles",
+			Err:       err,
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return &errors.DBError{
+			Operation: "commit",
+			Table:     "patches",
+			Err:       err,
+		}
+	}
+
+	return nil
+}
+
+// checkKnownCards returns a validation error naming the card IDs never seen in
+// the daily card aggregates nor in the retained battles
+func checkKnownCards(ctx context.Context, tx *sql.Tx, cardIDs []string) error {
+	if len(cardIDs) == 0 {
+		return nil
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id FROM unnest($1::text[]) AS id
+		WHERE NOT EXISTS (SELECT 1 FROM card_daily_stats WHERE card_id = id)
+		  AND NOT EXISTS (
+			SELECT 1 FROM battles
+			WHERE deck_cards @> jsonb_build_array(jsonb_build_object('id', id))
+		  )
+	`, pq.Array(cardIDs))
+	if err != nil {
+		return &errors.DBError{
+			Operation: "query_cards",
+			Table:     "card_daily_stats",
+			Err:       err,
+		}
+	}
+	defer rows.Close()
+
+	var unknown []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return &errors.DBError{
+				Operation: "scan_row",
+				Table:     "card_daily_stats",
+				Err:       err,
+			}
+		}
+		unknown = append(unknown, id)
+	}
+	if err := rows.Err(); err != nil {
+		return &errors.DBError{
+			Operation: "query_cards",
+			Table:     "card_daily_stats",
+			Err:       err,
+		}
+	}
+
+	if len(unknown) > 0 {
+		return &errors.ValidationError{
+			Field:   "affected_cards",
+			Message: fmt.Sprintf("unknown card IDs: %s", strings.Join(unknown, ", ")),
+		}
+	}
+	return nil
+}
+
+// List returns a page of patches, most recent first, with the number of patches
+func (r *PostgresPatchRepo) List(ctx context.Context, page models.Page) ([]*models.Patch, int, error) {
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM patches").Scan(&total); err != nil {
+		return nil, 0, &errors.DBError{
+			Operation: "count",
+			Table:     "patches",
+			Err:       err,
+		}
+	}
+
+	query := `
+		SELECT id, released_at, description, affected_cards, created_at
+		FROM patches
+		ORDER BY released_at DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, page.Limit, page.Offset)
+	if err != nil {
+		return nil, 0, &errors.DBError{
+			Operation: "query_all",
+			Table:     "patches",
+			Err:       err,
+		}
+	}
+	defer rows.Close()
+
+	patches := make([]*models.Patch, 0)
+	for rows.Next() {
+		patch, err := scanPatch(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		patches = append(patches, patch)
+	}
+
+	return patches, total, rows.Err()
+}
+
+func (r *PostgresPatchRepo) GetByID(ctx context.Context, id int) (*models.Patch, error) {
+	query := `
+		SELECT id, released_at, description, affected_cards, created_at
+		FROM patches
+		WHERE id = $1
+	`
+
+	patch, err := scanPatch(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return patch, nil
+}
+
+// Delete removes a patch and moves its battles back to the previous patch.
+// It returns false when the patch does not exist.
+func (r *PostgresPatchRepo) Delete(ctx context.Context, id int) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, &errors.DBError{
+			Operation: "begin_transaction",
+			Table:     "patches",
+			Err:       err,
+		}
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE battles SET patch_id = (
+			SELECT prev.id FROM patches prev, patches p
+			WHERE p.id = $1 AND prev.released_at < p.released_at
+			ORDER BY prev.released_at DESC
+			LIMIT 1
+		)
+		WHERE patch_id = $1
+	`, id)
+	if err != nil {
+		return false, &errors.DBError{
+			Operation: "untag_battles",
+			Table:     "battles",
+			Err:       err,
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM patches WHERE id = $1", id)
+	if err != nil {
+		return false, &errors.DBError{
+			Operation: "delete",
+			Table:     "patches",
+			Err:       err,
+		}
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return false, &errors.DBError{
+			Operation: "rows_affected",
+			Table:     "patches",
+			Err:       err,
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, &errors.DBError{
+			Operation: "commit",
+			Table:     "patches",
+			Err:       err,
+		}
+	}
+
+	return deleted > 0, nil
+}
+
+// Compare returns the win rates of cards and decks played at least minGames times
+// on both sides of patch, from the permanent daily aggregates so that patches
+// older than the battle retention can still be compared. Before covers the
+// complete days from the previous patch release to patch release, after the
+// complete days from patch release to the next patch release: the release days
+// mix both sides and are left out, as is the current day, not yet rolled up.
+func (r *PostgresPatchRepo) Compare(ctx context.Context, patch *models.Patch, minGames int) (*models.PatchComparison, error) {
+	comparison := &models.PatchComparison{
+		Patch: patch,
+		Cards: make([]*models.WinRateDelta, 0),
+		Decks: make([]*models.WinRateDelta, 0),
+	}
+
+	var beforeFrom, afterUntil sql.NullTime
+	err := r.db.QueryRowContext(ctx, `
+		SELECT
+			(SELECT MAX(released_at) FROM patches WHERE released_at < $1),
+			(SELECT MIN(released_at) FROM patches WHERE released_at > $1)
+	`, patch.ReleasedAt).Scan(&beforeFrom, &afterUntil)
+	if err != nil {
+		return nil, &errors.DBError{
+			Operation: "query_bounds",
+			Table:     "patches",
+			Err:       err,
+		}
+	}
+	if beforeFrom.Valid {
+		comparison.BeforeFrom = &beforeFrom.Time
+	}
+	if afterUntil.Valid {
+		comparison.AfterUntil = &afterUntil.Time
+	}
+
+	from := time.Time{}
+	if beforeFrom.Valid {
+		from = beforeFrom.Time
+	}
+	until := time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC)
+	if afterUntil.Valid {
+		until = afterUntil.Time
+	}
+
+	affected := make(map[string]bool, len(patch.AffectedCards))
+	for _, id := range patch.AffectedCards {
+		affected[id] = true
+	}
+
+	cardsQuery := `
+		SELECT
+			card_id,
+			MAX(card_name),
+			COALESCE(SUM(games) FILTER (WHERE day < $2), 0),
+			COALESCE(SUM(wins) FILTER (WHERE day < $2), 0),
+			COALESCE(SUM(games) FILTER (WHERE day > $2), 0),
+			COALESCE(SUM(wins) FILTER (WHERE day > $2), 0)
+		FROM card_daily_stats
+		WHERE day > $1 AND day < $3 AND day <> $2
+		GROUP BY card_id
+		HAVING COALESCE(SUM(games) FILTER (WHERE day < $2), 0) >= $4
+		   AND COALESCE(SUM(games) FILTER (WHERE day > $2), 0) >= $4
+	`
+	cards, err := r.queryDeltas(ctx, "card_daily_stats", cardsQuery,
+		sqlDate(from), sqlDate(patch.ReleasedAt), sqlDate(until), minGames)
+	if err != nil {
+		return nil, err
+	}
+	for _, card := range cards {
+		card.Affected = affected[card.Key]
+	}
+	comparison.Cards = append(comparison.Cards, cards...)
+
+	decksQuery := `
+		SELECT
+			deck_signature,
+			'',
+			COALESCE(SUM(games) FILTER (WHERE day < $2), 0),
+			COALESCE(SUM(wins) FILTER (WHERE day < $2), 0),
+			COALESCE(SUM(games) FILTER (WHERE day > $2), 0),
+			COALESCE(SUM(wins) FILTER (WHERE day > $2), 0)
+		FROM deck_daily_stats
+		WHERE day > $1 AND day < $3 AND day <> $2
+		GROUP BY deck_signature
+		HAVING COALESCE(SUM(games) FILTER (WHERE day < $2), 0) >= $4
+		   AND COALESCE(SUM(games) FILTER (WHERE day > $2), 0) >= $4
+	`
+	decks, err := r.queryDeltas(ctx, "deck_daily_stats", decksQuery,
+		sqlDate(from), sqlDate(patch.ReleasedAt), sqlDate(until), minGames)
+	if err != nil {
+		return nil, err
+	}
+	comparison.Decks = append(comparison.Decks, decks...)
+
+	return comparison, nil
+}
+
+// sqlDate returns the UTC date of t as a DATE literal
+func sqlDate(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
+
+// queryDeltas runs a query on table returning key, name, games and wins before then after
+// the patch, and computes the win rate deltas sorted by decreasing absolute delta
+func (r *PostgresPatchRepo) queryDeltas(ctx context.Context, table, query string, args ...any) ([]*models.WinRateDelta, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, &errors.DBError{
+			Operation: "query_compare",
+			Table:     table,
+			Err:       err,
+		}
+	}
+	defer rows.Close()
+
+	var deltas []*models.WinRateDelta
+	for rows.Next() {
+		var delta models.WinRateDelta
+		var name sql.NullString
+		var beforeWins, afterWins int
+		err := rows.Scan(
+			&delta.Key,
+			&name,
+			&delta.BeforeGames,
+			&beforeWins,
+			&delta.AfterGames,
+			&afterWins,
+		)
+		if err != nil {
+			return nil, &errors.DBError{
+				Operation: "scan_row",
+				Table:     table,
+				Err:       err,
+			}
+		}
+
+		delta.Name = name.String
+		delta.BeforeWinRate = winRate(beforeWins, delta.BeforeGames)
+		delta.AfterWinRate = winRate(afterWins, delta.AfterGames)
+		delta.Delta = math.Round((delta.AfterWinRate-delta.BeforeWinRate)*100) / 100
+		deltas = append(deltas, &delta)
+	}
+
+	sort.Slice(deltas, func(i, j int) bool {
+		return math.Abs(deltas[i].Delta) > math.Abs(deltas[j].Delta)
+	})
+
+	return deltas, rows.Err()
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanPatch(row rowScanner) (*models.Patch, error) {
+	var patch models.Patch
+	var description sql.NullString
+	var cardsJSON []byte
+
+	err := row.Scan(
+		&patch.ID,
+		&patch.ReleasedAt,
+		&description,
+		&cardsJSON,
+		&patch.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, &errors.DBError{
+			Operation: "scan_row",
+			Table:     "patches",
+			Err:       err,
+		}
+	}
+
+	patch.Description = description.String
+	if err := json.Unmarshal(cardsJSON, &patch.AffectedCards); err != nil {
+		return nil, &errors.DBError{
+			Operation: "unmarshal_cards",
+			Table:     "patches",
+			Err:       err,
+		}
+	}
+
+	return &patch, nil
+}
+
+// winRate returns wins/games as a percentage rounded to 2 decimals
+func winRate(wins, games int) float64 {
+	if games == 0 {
+		return 0
+	}
+	return math.Round(float64(wins)/float64(games)*10000) / 100
+}
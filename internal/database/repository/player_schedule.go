@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/leopoldhub/royal-api-personal/internal/errors"
+	"github.com/leopoldhub/royal-api-personal/internal/models"
+)
+
+type PostgresPlayerScheduleRepo struct {
+	db *sql.DB
+}
+
+var _ PlayerScheduleRepository = (*PostgresPlayerScheduleRepo)(nil)
+
+func NewPlayerScheduleRepository(db *sql.DB) PlayerScheduleRepository {
+	return &PostgresPlayerScheduleRepo{db: db}
+}
+
+func (r *PostgresPlayerScheduleRepo) GetAll(ctx context.Context) (map[string]*models.PlayerSchedule, error) {
+	query := `
+		SELECT player_tag, battles_per_hour, window_fill,
+			   last_battle_time, last_fetched_at, next_fetch_at
+		FROM player_schedule
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, &errors.DBError{
+			Operation: "query_all",
+			Table:     "player_schedule",
+			Err:       err,
+		}
+	}
+	defer rows.Close()
+
+	schedules := make(map[string]*models.PlayerSchedule)
+	for rows.Next() {
+		var schedule models.PlayerSchedule
+		var lastBattle, lastFetched sql.NullTime
+
+		err := rows.Scan(
+			&schedule.PlayerTag,
+			&schedule.BattlesPerHour,
+			&schedule.WindowFill,
+			&lastBattle,
+			&lastFetched,
+			&schedule.NextFetchAt,
+		)
+		if err != nil {
+			return nil, &errors.DBError{
+				Operation: "scan_row",
+				Table:     "player_schedule",
+				Err:       err,
+			}
+		}
+
+		schedule.LastBattleTime = lastBattle.Time
+		schedule.LastFetchedAt = lastFetched.Time
+		schedules[schedule.PlayerTag] = &schedule
+	}
+
+	return schedules, rows.Err()
+}
+
+func (r *PostgresPlayerScheduleRepo) Upsert(ctx context.Context, schedules []*models.PlayerSchedule) error {
+	if len(schedules) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return &errors.DBError{
+			Operation: "begin_transaction",
+			Table:     "player_schedule",
+			Err:       err,
+		}
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO player_schedule (
+			player_tag, battles_per_hour, window_fill,
+			last_battle_time, last_fetched_at, next_fetch_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		ON CONFLICT (player_tag) DO UPDATE SET
+			battles_per_hour = EXCLUDED.battles_per_hour,
+			window_fill = EXCLUDED.window_fill,
+			last_battle_time = EXCLUDED.last_battle_time,
+			last_fetched_at = EXCLUDED.last_fetched_at,
+			next_fetch_at = EXCLUDED.next_fetch_at,
+			updated_at = NOW()
+	`)
+	if err != nil {
+		return &errors.DBError{
+			Operation: "prepare_statement",
+			Table:     "player_schedule",
+			Err:       err,
+		}
+	}
+	defer stmt.Close()
+
+	for _, schedule := range schedules {
+		_, err := stmt.ExecContext(ctx,
+			schedule.PlayerTag,
+			schedule.BattlesPerHour,
+			schedule.WindowFill,
+			nullTime(schedule.LastBattleTime),
+			nullTime(schedule.LastFetchedAt),
+			schedule.NextFetchAt,
+		)
+		if err != nil {
+			return &errors.DBError{
+				Operation: "exec_upsert",
+				Table:     "player_schedule",
+				Err:       err,
+			}
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/leopoldhub/royal-api-personal/internal/errors"
+	"github.com/leopoldhub/royal-api-personal/internal/models"
+)
+
+type PostgresRawBattlelogRepo struct {
+	db *sql.DB
+}
+
+var _ RawBattlelogRepository = (*PostgresRawBattlelogRepo)(nil)
+
+func NewRawBattlelogRepository(db *sql.DB) RawBattlelogRepository {
+	return &PostgresRawBattlelogRepo{db: db}
+}
+
+func (r *PostgresRawBattlelogRepo) Insert(ctx context.Context, battlelog *models.RawBattlelog) error {
+	query := `
+		INSERT INTO raw_battlelogs (player_tag, fetched_at, battle_count, payload)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		battlelog.PlayerTag,
+		battlelog.FetchedAt,
+		battlelog.BattleCount,
+		battlelog.Payload,
+	).Scan(&battlelog.ID)
+	if err != nil {
+		return &errors.DBError{
+			Operation: "insert",
+			Table:     "raw_battlelogs",
+			Err:       err,
+		}
+	}
+
+	return nil
+}
+
+// List returns up to limit archived battlelogs with an id greater than afterID, oldest first
+func (r *PostgresRawBattlelogRepo) List(ctx context.Context, afterID int64, limit int) ([]*models.RawBattlelog, error) {
+	query := `
+		SELECT id, player_tag, fetched_at, battle_count, payload
+		FROM raw_battlelogs
+		WHERE id > $1
+		ORDER BY id
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, &errors.DBError{
+			Operation: "list",
+			Table:     "raw_battlelogs",
+			Err:       err,
+		}
+	}
+	defer rows.Close()
+
+	var battlelogs []*models.RawBattlelog
+	for rows.Next() {
+		battlelog := &models.RawBattlelog{}
+		err := rows.Scan(
+			&battlelog.ID,
+			&battlelog.PlayerTag,
+			&battlelog.FetchedAt,
+			&battlelog.BattleCount,
+			&battlelog.Payload,
+		)
+		if err != nil {
+			return nil, &errors.DBError{
+				Operation: "scan_row",
+				Table:     "raw_battlelogs",
+				Err:       err,
+			}
+		}
+		battlelogs = append(battlelogs, battlelog)
+	}
+
+	return battlelogs, rows.Err()
+}
+
+func (r *PostgresRawBattlelogRepo) DeleteOlderThan(ctx context.Context, days int) (int64, error) {
+	query := `
+		DELETE FROM raw_battlelogs
+		WHERE fetched_at < (NOW() AT TIME ZONE 'UTC') - INTERVAL '1 day' * $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query, days)
+	if err != nil {
+		return 0, &errors.DBError{
+			Operation: "delete_old",
+			Table:     "raw_battlelogs",
+			Err:       err,
+		}
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/leopoldhub/royal-api-personal/internal/errors"
+	"github.com/leopoldhub/royal-api-personal/internal/models"
+)
+
+type PostgresRejectedBattleRepo struct {
+	db *sql.DB
+}
+
+var _ RejectedBattleRepository = (*PostgresRejectedBattleRepo)(nil)
+
+func NewRejectedBattleRepository(db *sql.DB) RejectedBattleRepository {
+	return &PostgresRejectedBattleRepo{db: db}
+}
+
+func (r *PostgresRejectedBattleRepo) Insert(ctx context.Context, rejected []*models.RejectedBattle) error {
+	if len(rejected) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return &errors.DBError{
+			Operation: "begin_transaction",
+			Table:     "raw_battles_rejected",
+			Err:       err,
+		}
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO raw_battles_rejected (player_tag, battle_time, reason, detail, raw)
+		VALUES ($1, $2, $3, $4, $5)
+	`)
+	if err != nil {
+		return &errors.DBError{
+			Operation: "prepare_statement",
+			Table:     "raw_battles_rejected",
+			Err:       err,
+		}
+	}
+	defer stmt.Close()
+
+	for _, battle := range rejected {
+		_, err := stmt.ExecContext(ctx,
+			battle.PlayerTag,
+			battle.BattleTime,
+			battle.Reason,
+			nullString(battle.Detail),
+			[]byte(battle.Raw),
+		)
+		if err != nil {
+			return &errors.DBError{
+				Operation: "exec_insert",
+				Table:     "raw_battles_rejected",
+				Err:       err,
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *PostgresRejectedBattleRepo) CountByReason(ctx context.Context, days int) (map[string]int, error) {
+	query := `
+		SELECT reason, COUNT(*)
+		FROM raw_battles_rejected
+		WHERE rejected_at >= NOW() - INTERVAL '1 day' * $1
+		GROUP BY reason
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, days)
+	if err != nil {
+		return nil, &errors.DBError{
+			Operation: "count_by_reason",
+			Table:     "raw_battles_rejected",
+			Err:       err,
+		}
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var reason string
+		var count int
+		if err := rows.Scan(&reason, &count); err != nil {
+			return nil, &errors.DBError{
+				Operation: "scan_row",
+				Table:     "raw_battles_rejected",
+				Err:       err,
+			}
+		}
+		counts[reason] = count
+	}
+
+	return counts, rows.Err()
+}
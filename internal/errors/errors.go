@@ -40,3 +40,28 @@ type ValidationError struct {
 func (e *ValidationError) Error() string {
 	return fmt.Sprintf("validation error on %s: %s", e.Field, e.Message)
 }
+
+// ParseError represents a raw API payload rejected by the parser
+type ParseError struct {
+	Reason  string
+	Message string
+	Err     error
+}
+
+func (e *ParseError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("parse error [%s]: %s: %v", e.Reason, e.Message, e.Err)
+	}
+	return fmt.Sprintf("parse error [%s]: %s", e.Reason, e.Message)
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// AuthError represents a rejected API credential
+type AuthError struct {
+	Message string
+}
+
+func (e *AuthError) Error() string {
+	return fmt.Sprintf("authentication failed: %s", e.Message)
+}
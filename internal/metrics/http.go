@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ContentType of the text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Handler serves the metrics of registries, in order
+func Handler(registries ...*Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		for _, registry := range registries {
+			if err := registry.WriteText(&buf); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", ContentType)
+		w.WriteHeader(http.StatusOK)
+		w.Write(buf.Bytes())
+	})
+}
+
+// Serve exposes Handler(registries...) on GET /metrics at addr until ctx is
+// canceled, for processes without the API server such as collect-loop
+func Serve(ctx context.Context, addr string, registries ...*Registry) error {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", Handler(registries...))
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+		WriteTimeout:      10 * time.Second,
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errc:
+		return fmt.Errorf("failed to serve metrics on %s: %w", addr, err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errc; !stderrors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// RegisterDBStats exposes the connection pool statistics of db (sql.DB.Stats)
+func RegisterDBStats(r *Registry, db *sql.DB) {
+	stat := func(fn func(sql.DBStats) float64) func() float64 {
+		return func() float64 { return fn(db.Stats()) }
+	}
+
+	r.Func("royal_db_max_open_connections", "Maximum number of open connections to the database",
+		TypeGauge, stat(func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) }))
+	r.Func("royal_db_open_connections", "Established connections, in use and idle",
+		TypeGauge, stat(func(s sql.DBStats) float64 { return float64(s.OpenConnections) }))
+	r.Func("royal_db_in_use_connections", "Connections currently in use",
+		TypeGauge, stat(func(s sql.DBStats) float64 { return float64(s.InUse) }))
+	r.Func("royal_db_idle_connections", "Idle connections",
+		TypeGauge, stat(func(s sql.DBStats) float64 { return float64(s.Idle) }))
+	r.Func("royal_db_wait_count_total", "Connections waited for because the pool was exhausted",
+		TypeCounter, stat(func(s sql.DBStats) float64 { return float64(s.WaitCount) }))
+	r.Func("royal_db_wait_duration_seconds_total", "Time blocked waiting for a connection",
+		TypeCounter, stat(func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() }))
+	r.Func("royal_db_max_idle_closed_total", "Connections closed because of the idle pool size",
+		TypeCounter, stat(func(s sql.DBStats) float64 { return float64(s.MaxIdleClosed) }))
+	r.Func("royal_db_max_idle_time_closed_total", "Connections closed because of the idle time limit",
+		TypeCounter, stat(func(s sql.DBStats) float64 { return float64(s.MaxIdleTimeClosed) }))
+	r.Func("royal_db_max_lifetime_closed_total", "Connections closed because of the lifetime limit",
+		TypeCounter, stat(func(s sql.DBStats) float64 { return float64(s.MaxLifetimeClosed) }))
+}
@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	r := NewRegistry()
+	requests := r.Counter("http_requests_total", "Requests by route", "route", "status")
+	latency := r.Histogram("http_request_duration_seconds", "Latency", []float64{0.5, 0.1}, "route")
+	r.Func("db_open_connections", "Open connections", TypeGauge, func() float64 { return 3 })
+
+	requests.Inc("GET /decks/meta", "200")
+	requests.Add(2, "GET /decks/meta", "200")
+	requests.Inc(`GET /a"b\c`, "500")
+	latency.Observe(0.05, "GET /decks/meta")
+	latency.Observe(0.3, "GET /decks/meta")
+	latency.Observe(2, "GET /decks/meta")
+
+	var out strings.Builder
+	if err := r.WriteText(&out); err != nil {
+		t.Fatalf("WriteText: %v", err)
+	}
+
+	want := `# HELP http_requests_total Requests by route
+# TYPE http_requests_total counter
+http_requests_total{route="GET /a\"b\\c",status="500"} 1
+http_requests_total{route="GET /decks/meta",status="200"} 3
+# HELP http_request_duration_seconds Latency
+# TYPE http_request_duration_seconds histogram
+http_request_duration_seconds_bucket{route="GET /decks/meta",le="0.1"} 1
+http_request_duration_seconds_bucket{route="GET /decks/meta",le="0.5"} 2
+http_request_duration_seconds_bucket{route="GET /decks/meta",le="+Inf"} 3
+http_request_duration_seconds_sum{route="GET /decks/meta"} 2.35
+http_request_duration_seconds_count{route="GET /decks/meta"} 3
+# HELP db_open_connections Open connections
+# TYPE db_open_connections gauge
+db_open_connections 3
+`
+	if out.String() != want {
+		t.Errorf("output:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestGauge_Unlabeled(t *testing.T) {
+	r := NewRegistry()
+	g := r.Gauge("last_success_timestamp_seconds", "Last success\nin seconds")
+	g.Set(1.7e9)
+
+	var out strings.Builder
+	r.WriteText(&out)
+
+	if !strings.Contains(out.String(), "# HELP last_success_timestamp_seconds Last success\\nin seconds\n") {
+		t.Errorf("help not escaped:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "\nlast_success_timestamp_seconds 1.7e+09\n") {
+		t.Errorf("gauge value missing:\n%s", out.String())
+	}
+}
+
+func TestRegister_DuplicatePanics(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("runs_total", "Runs")
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering runs_total twice did not panic")
+		}
+	}()
+	r.Gauge("runs_total", "Runs")
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// RawBattlelog is an archived battlelog of a player, as returned by the API
+type RawBattlelog struct {
+	ID          int64     `json:"id,omitempty"`
+	PlayerTag   string    `json:"player_tag"`
+	FetchedAt   time.Time `json:"fetched_at"`
+	BattleCount int       `json:"battle_count"`
+	Payload     []byte    `json:"-"` // gzip-compressed JSON array of battles
+}
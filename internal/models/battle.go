@@ -14,5 +14,20 @@ type Battle struct {
 	DeckSignature  string    `json:"deck_signature"`
 	DeckCards      [8]Card   `json:"deck_cards"`
 	IsVictory      bool      `json:"is_victory"`
+	PatchID        *int      `json:"patch_id,omitempty"` // patch active at battle_time
 	CreatedAt      time.Time `json:"created_at,omitempty"`
 }
+
+// InsertSummary reports the outcome of a batch insertion
+type InsertSummary struct {
+	Inserted   int `json:"inserted"`   // battles actually new in database
+	Duplicates int `json:"duplicates"` // battles already stored or repeated in the batch
+	Skipped    int `json:"skipped"`    // battles that could not be encoded or are older than the partitions
+}
+
+// Add accumulates another summary
+func (s *InsertSummary) Add(other InsertSummary) {
+	s.Inserted += other.Inserted
+	s.Duplicates += other.Duplicates
+	s.Skipped += other.Skipped
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// Scopes granted to API clients
+const (
+	ScopeReadDecks   = "read:decks"   // decks and patches
+	ScopeReadStats   = "read:stats"   // collection statistics
+	ScopeReadMetrics = "read:metrics" // Prometheus metrics of GET /metrics
+	ScopeAdmin       = "admin"        // every scope, patch and client management
+)
+
+// RateLimitAuth keys the per IP rate limit of protected routes, applied before
+// the token is checked so that tokens cannot be brute-forced
+const RateLimitAuth = "auth"
+
+// Scopes lists every scope an API client can be granted
+var Scopes = []string{ScopeReadDecks, ScopeReadStats, ScopeReadMetrics, ScopeAdmin}
+
+// APIClient is a consumer of the REST API authenticated by its own token
+type APIClient struct {
+	ID        int        `json:"id"`
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at"` // nil when the token never expires
+	RevokedAt *time.Time `json:"revoked_at"`
+}
+
+// HasScope reports whether the client is granted scope, admin granting every scope
+func (c *APIClient) HasScope(scope string) bool {
+	return slices.Contains(c.Scopes, ScopeAdmin) || slices.Contains(c.Scopes, scope)
+}
+
+// Expired reports whether the token of the client expired at now
+func (c *APIClient) Expired(now time.Time) bool {
+	return c.ExpiresAt != nil && !now.Before(*c.ExpiresAt)
+}
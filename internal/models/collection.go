@@ -10,7 +10,28 @@ type CollectionStats struct {
 	PlayersProcessed int       `json:"players_processed"`
 	BattlesCollected int       `json:"battles_collected"`
 	BattlesStored    int       `json:"battles_stored"`
+	BattlesDuplicate int       `json:"battles_duplicate"`
+	BattlesSkipped   int       `json:"battles_skipped"`
+	PlayersChecked   int       `json:"players_checked"`
+	PlayersWithGaps  int       `json:"players_with_gaps"`
 	Errors           int       `json:"errors"`
 	Status           string    `json:"status"`
 	ErrorMessage     string    `json:"error_message,omitempty"`
 }
+
+// Collection run statuses
+const (
+	CollectionRunning   = "running"
+	CollectionCompleted = "completed"
+	CollectionFailed    = "failed"
+	CollectionAbandoned = "abandoned"
+)
+
+// Coverage returns the percentage of checked players whose battlelog did not overflow
+// since the previous fetch. A run without checked players has full coverage.
+func (s *CollectionStats) Coverage() float64 {
+	if s.PlayersChecked == 0 {
+		return 100
+	}
+	return float64(s.PlayersChecked-s.PlayersWithGaps) / float64(s.PlayersChecked) * 100
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// DeckHistoryPoint holds the statistics of a deck over one interval (day or week)
+type DeckHistoryPoint struct {
+	Period     time.Time `json:"period"`
+	Games      int       `json:"games"`
+	Wins       int       `json:"wins"`
+	WinRate    float64   `json:"win_rate"`
+	UsageShare float64   `json:"usage_share"` // percentage of all games of the period
+}
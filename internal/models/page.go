@@ -0,0 +1,7 @@
+package models
+
+// Page selects a slice of a list ordered by the repository
+type Page struct {
+	Limit  int
+	Offset int
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Patch is a balance change released by Supercell
+type Patch struct {
+	ID            int       `json:"id"`
+	ReleasedAt    time.Time `json:"released_at"`
+	Description   string    `json:"description,omitempty"`
+	AffectedCards []string  `json:"affected_cards"`
+	CreatedAt     time.Time `json:"created_at,omitempty"`
+}
+
+// PatchComparison compares win rates before and after a patch
+type PatchComparison struct {
+	Patch      *Patch          `json:"patch"`
+	BeforeFrom *time.Time      `json:"before_from"` // release of the previous patch, nil when unknown
+	AfterUntil *time.Time      `json:"after_until"` // release of the next patch, nil for the current one
+	Cards      []*WinRateDelta `json:"cards"`
+	Decks      []*WinRateDelta `json:"decks"`
+}
+
+// WinRateDelta is the win rate of a card or deck on both sides of a patch
+type WinRateDelta struct {
+	Key           string  `json:"key"` // card ID or deck signature
+	Name          string  `json:"name,omitempty"`
+	BeforeGames   int     `json:"before_games"`
+	AfterGames    int     `json:"after_games"`
+	BeforeWinRate float64 `json:"before_win_rate"`
+	AfterWinRate  float64 `json:"after_win_rate"`
+	Delta         float64 `json:"delta"`
+	Affected      bool    `json:"affected,omitempty"` // card changed by the patch
+}
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// RejectedBattle is a raw battle the parser could not convert, kept for diagnostics
+type RejectedBattle struct {
+	ID         int             `json:"id,omitempty"`
+	PlayerTag  string          `json:"player_tag"`
+	BattleTime string          `json:"battle_time"`
+	Reason     string          `json:"reason"`
+	Detail     string          `json:"detail,omitempty"`
+	Raw        json.RawMessage `json:"raw"`
+	RejectedAt time.Time       `json:"rejected_at,omitempty"`
+}
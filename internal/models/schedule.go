@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// PlayerSchedule tracks the polling state of a tracked player
+type PlayerSchedule struct {
+	PlayerTag      string    `json:"player_tag"`
+	BattlesPerHour float64   `json:"battles_per_hour"`
+	WindowFill     float64   `json:"window_fill"`
+	LastBattleTime time.Time `json:"last_battle_time,omitempty"`
+	LastFetchedAt  time.Time `json:"last_fetched_at,omitempty"`
+	NextFetchAt    time.Time `json:"next_fetch_at"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// DeckWindowStats compares the games of a deck in a recent window against a
+// baseline window. Totals are the games of every deck in each window.
+type DeckWindowStats struct {
+	Signature     string
+	Cards         [8]Card
+	RecentGames   int
+	RecentWins    int
+	BaselineGames int
+	BaselineWins  int
+	RecentTotal   int
+	BaselineTotal int
+	FirstSeen     time.Time
+}
+
+// TrendingDeck is a deck whose usage grew significantly in the recent window
+type TrendingDeck struct {
+	Signature       string    `json:"signature"`
+	Cards           [8]Card   `json:"cards"`
+	RecentGames     int       `json:"recent_games"`
+	BaselineGames   int       `json:"baseline_games"`
+	RecentUsage     float64   `json:"recent_usage"`   // percentage of games in the recent window
+	BaselineUsage   float64   `json:"baseline_usage"` // percentage of games in the baseline window
+	RecentWinRate   float64   `json:"recent_win_rate"`
+	BaselineWinRate float64   `json:"baseline_win_rate"`
+	Score           float64   `json:"score"` // z-score of the usage growth
+	Emerging        bool      `json:"emerging"`
+	FirstSeen       time.Time `json:"first_seen"`
+}
@@ -0,0 +1,9 @@
+package models
+
+import "time"
+
+// DataVersion identifies the state of the data served by the read endpoints
+type DataVersion struct {
+	Version   int64
+	UpdatedAt time.Time
+}
@@ -0,0 +1,6 @@
+-- Royal API Personnel - Initial Schema (rollback)
+-- Version: 001
+
+DROP TABLE IF EXISTS collection_stats;
+DROP TABLE IF EXISTS meta_decks;
+DROP TABLE IF EXISTS battles;
@@ -0,0 +1,4 @@
+-- Royal API Personnel - Adaptive polling (rollback)
+-- Version: 002
+
+DROP TABLE IF EXISTS player_schedule;
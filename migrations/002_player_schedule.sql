@@ -0,0 +1,20 @@
+-- Royal API Personnel - Adaptive polling
+-- Version: 002
+-- Date: 2026-01-18
+
+-- Table: player_schedule
+-- Per-player battle rate and next battlelog fetch time
+CREATE TABLE IF NOT EXISTS player_schedule (
+    player_tag VARCHAR(20) PRIMARY KEY,
+    battles_per_hour DECIMAL(8,3) NOT NULL DEFAULT 0,
+    window_fill DECIMAL(5,2) NOT NULL DEFAULT 0,
+    last_battle_time TIMESTAMP,
+    last_fetched_at TIMESTAMP,
+    next_fetch_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_player_schedule_next ON player_schedule(next_fetch_at);
+
+COMMENT ON TABLE player_schedule IS 'Adaptive polling state: battle rate and next fetch per tracked player';
+COMMENT ON COLUMN player_schedule.window_fill IS 'Share of the 25-battle window filled by new battles at last fetch (1 = possible overflow)';
@@ -0,0 +1,5 @@
+-- Royal API Personnel - Battlelog gap detection (rollback)
+-- Version: 003
+
+ALTER TABLE collection_stats DROP COLUMN IF EXISTS players_with_gaps;
+ALTER TABLE collection_stats DROP COLUMN IF EXISTS players_checked;
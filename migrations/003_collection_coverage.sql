@@ -0,0 +1,9 @@
+-- Royal API Personnel - Battlelog gap detection
+-- Version: 003
+-- Date: 2026-01-20
+
+ALTER TABLE collection_stats ADD COLUMN IF NOT EXISTS players_checked INT DEFAULT 0;
+ALTER TABLE collection_stats ADD COLUMN IF NOT EXISTS players_with_gaps INT DEFAULT 0;
+
+COMMENT ON COLUMN collection_stats.players_checked IS 'Players fetched that already had stored battles (gap detection possible)';
+COMMENT ON COLUMN collection_stats.players_with_gaps IS 'Players whose oldest fetched battle is newer than their latest stored battle';
@@ -0,0 +1,5 @@
+-- Royal API Personnel - Resumable collection runs (rollback)
+-- Version: 004
+
+DROP INDEX IF EXISTS idx_collection_status;
+DROP TABLE IF EXISTS collection_checkpoints;
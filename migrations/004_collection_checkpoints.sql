@@ -0,0 +1,17 @@
+-- Royal API Personnel - Resumable collection runs
+-- Version: 004
+-- Date: 2026-01-22
+
+-- Table: collection_checkpoints
+-- Players whose battles were committed during a collection run
+CREATE TABLE IF NOT EXISTS collection_checkpoints (
+    collection_id INT NOT NULL REFERENCES collection_stats(id) ON DELETE CASCADE,
+    player_tag VARCHAR(20) NOT NULL,
+    processed_at TIMESTAMP DEFAULT NOW(),
+
+    PRIMARY KEY (collection_id, player_tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_collection_status ON collection_stats(status, started_at DESC);
+
+COMMENT ON TABLE collection_checkpoints IS 'Processed player tags per run, used to resume an interrupted collection';
@@ -0,0 +1,5 @@
+-- Royal API Personnel - Inserted vs duplicate accounting (rollback)
+-- Version: 005
+
+ALTER TABLE collection_stats DROP COLUMN IF EXISTS battles_skipped;
+ALTER TABLE collection_stats DROP COLUMN IF EXISTS battles_duplicate;
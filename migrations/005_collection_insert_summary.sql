@@ -0,0 +1,10 @@
+-- Royal API Personnel - Inserted vs duplicate accounting
+-- Version: 005
+-- Date: 2026-01-24
+
+ALTER TABLE collection_stats ADD COLUMN IF NOT EXISTS battles_duplicate INT DEFAULT 0;
+ALTER TABLE collection_stats ADD COLUMN IF NOT EXISTS battles_skipped INT DEFAULT 0;
+
+COMMENT ON COLUMN collection_stats.battles_stored IS 'Battles actually new in database';
+COMMENT ON COLUMN collection_stats.battles_duplicate IS 'Parsed battles that were already stored';
+COMMENT ON COLUMN collection_stats.battles_skipped IS 'Parsed battles dropped because their cards could not be encoded';
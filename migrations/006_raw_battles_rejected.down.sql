@@ -0,0 +1,4 @@
+-- Royal API Personnel - Parser dead-letter storage (rollback)
+-- Version: 006
+
+DROP TABLE IF EXISTS raw_battles_rejected;
@@ -0,0 +1,20 @@
+-- Royal API Personnel - Parser dead-letter storage
+-- Version: 006
+-- Date: 2026-01-26
+
+-- Table: raw_battles_rejected
+-- Raw battles rejected by the parser, to spot API format changes
+CREATE TABLE IF NOT EXISTS raw_battles_rejected (
+    id SERIAL PRIMARY KEY,
+    player_tag VARCHAR(20),
+    battle_time VARCHAR(32),
+    reason VARCHAR(50) NOT NULL,
+    detail TEXT,
+    raw JSONB NOT NULL,
+    rejected_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_rejected_reason ON raw_battles_rejected(reason, rejected_at DESC);
+
+COMMENT ON TABLE raw_battles_rejected IS 'Dead letters: raw battles the parser could not convert';
+COMMENT ON COLUMN raw_battles_rejected.battle_time IS 'Unparsed API timestamp, kept as text since it may be the cause of the rejection';
@@ -0,0 +1,4 @@
+-- Royal API Personnel - Raw battlelog archive (rollback)
+-- Version: 007
+
+DROP TABLE IF EXISTS raw_battlelogs;
@@ -0,0 +1,19 @@
+-- Royal API Personnel - Raw battlelog archive
+-- Version: 007
+-- Date: 2026-01-27
+
+-- Table: raw_battlelogs
+-- Battlelogs as returned by the API (gzip-compressed JSON), one row per player per fetch.
+-- Used to re-run the parser over history with the reparse command.
+CREATE TABLE IF NOT EXISTS raw_battlelogs (
+    id BIGSERIAL PRIMARY KEY,
+    player_tag VARCHAR(20) NOT NULL,
+    fetched_at TIMESTAMP NOT NULL,
+    battle_count INTEGER NOT NULL,
+    payload BYTEA NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_raw_battlelogs_fetched_at ON raw_battlelogs(fetched_at);
+
+COMMENT ON TABLE raw_battlelogs IS 'Archive of raw battlelogs, enabled with ARCHIVE_RAW=true';
+COMMENT ON COLUMN raw_battlelogs.payload IS 'gzip-compressed JSON array of battles, byte-for-byte as received';
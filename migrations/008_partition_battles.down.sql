@@ -0,0 +1,46 @@
+-- Royal API Personnel - Daily partitions for battles (rollback)
+-- Version: 008
+
+-- Convert battles back to a regular table, moving existing rows
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_partitioned_table WHERE partrelid = 'battles'::regclass
+    ) THEN
+        RETURN;
+    END IF;
+
+    ALTER TABLE battles RENAME TO battles_partitioned;
+    ALTER TABLE battles_partitioned RENAME CONSTRAINT battles_pkey TO battles_partitioned_pkey;
+    ALTER TABLE battles_partitioned RENAME CONSTRAINT unique_player_battle TO unique_player_battle_partitioned;
+    ALTER SEQUENCE battles_id_seq OWNED BY NONE;
+
+    CREATE TABLE battles (
+        id INT PRIMARY KEY DEFAULT nextval('battles_id_seq'),
+        battle_time TIMESTAMP NOT NULL,
+        player_tag VARCHAR(20) NOT NULL,
+        opponent_tag VARCHAR(20),
+        game_mode VARCHAR(50),
+        player_crowns INT,
+        opponent_crowns INT,
+        deck_signature VARCHAR(255) NOT NULL,
+        deck_cards JSONB NOT NULL,
+        is_victory BOOLEAN,
+        created_at TIMESTAMP DEFAULT NOW(),
+
+        CONSTRAINT unique_player_battle UNIQUE (player_tag, battle_time)
+    );
+
+    ALTER SEQUENCE battles_id_seq OWNED BY battles.id;
+
+    INSERT INTO battles SELECT * FROM battles_partitioned;
+    DROP TABLE battles_partitioned;
+END;
+$$;
+
+CREATE INDEX IF NOT EXISTS idx_battles_time ON battles(battle_time);
+CREATE INDEX IF NOT EXISTS idx_battles_deck ON battles(deck_signature);
+CREATE INDEX IF NOT EXISTS idx_battles_player ON battles(player_tag);
+
+DROP FUNCTION IF EXISTS purge_battles_before(TIMESTAMP);
+DROP FUNCTION IF EXISTS ensure_battles_partitions(DATE, DATE);
@@ -0,0 +1,114 @@
+-- Royal API Personnel - Daily partitions for battles
+-- Version: 008
+-- Date: 2026-01-29
+
+-- Function: ensure_battles_partitions
+-- Creates the missing daily partitions battles_pYYYYMMDD covering [from_day, to_day]
+CREATE OR REPLACE FUNCTION ensure_battles_partitions(from_day DATE, to_day DATE)
+RETURNS INT AS $$
+DECLARE
+    day DATE := from_day;
+    created INT := 0;
+    partition_name TEXT;
+BEGIN
+    WHILE day <= to_day LOOP
+        partition_name := 'battles_p' || to_char(day, 'YYYYMMDD');
+        IF to_regclass(partition_name) IS NULL THEN
+            EXECUTE format(
+                'CREATE TABLE IF NOT EXISTS %I PARTITION OF battles FOR VALUES FROM (%L) TO (%L)',
+                partition_name, day, day + 1
+            );
+            created := created + 1;
+        END IF;
+        day := day + 1;
+    END LOOP;
+    RETURN created;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function: purge_battles_before
+-- Retention: drops the daily partitions entirely older than cutoff, then deletes the
+-- remaining old rows of the partition straddling cutoff. Returns the rows removed.
+CREATE OR REPLACE FUNCTION purge_battles_before(cutoff TIMESTAMP)
+RETURNS BIGINT AS $$
+DECLARE
+    part RECORD;
+    rows_in_partition BIGINT;
+    removed BIGINT := 0;
+BEGIN
+    FOR part IN
+        SELECT c.relname
+        FROM pg_inherits i
+        JOIN pg_class c ON c.oid = i.inhrelid
+        WHERE i.inhparent = 'battles'::regclass
+          AND c.relname ~ '^battles_p[0-9]{8}$'
+          AND to_date(substring(c.relname FROM 10), 'YYYYMMDD') + 1 <= cutoff
+    LOOP
+        EXECUTE format('SELECT COUNT(*) FROM %I', part.relname) INTO rows_in_partition;
+        EXECUTE format('DROP TABLE %I', part.relname);
+        removed := removed + rows_in_partition;
+    END LOOP;
+
+    DELETE FROM battles WHERE battle_time < cutoff;
+    GET DIAGNOSTICS rows_in_partition = ROW_COUNT;
+
+    RETURN removed + rows_in_partition;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Convert battles to a table partitioned by day, moving existing rows.
+-- Skipped when battles is already partitioned.
+DO $$
+DECLARE
+    first_day DATE;
+    last_day DATE;
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM pg_partitioned_table WHERE partrelid = 'battles'::regclass
+    ) THEN
+        RETURN;
+    END IF;
+
+    ALTER TABLE battles RENAME TO battles_legacy;
+    ALTER TABLE battles_legacy RENAME CONSTRAINT battles_pkey TO battles_legacy_pkey;
+    ALTER TABLE battles_legacy RENAME CONSTRAINT unique_player_battle TO unique_player_battle_legacy;
+    ALTER SEQUENCE battles_id_seq OWNED BY NONE;
+
+    -- Unique constraints of a partitioned table must include the partition key
+    CREATE TABLE battles (
+        id INT NOT NULL DEFAULT nextval('battles_id_seq'),
+        battle_time TIMESTAMP NOT NULL,
+        player_tag VARCHAR(20) NOT NULL,
+        opponent_tag VARCHAR(20),
+        game_mode VARCHAR(50),
+        player_crowns INT,
+        opponent_crowns INT,
+        deck_signature VARCHAR(255) NOT NULL,
+        deck_cards JSONB NOT NULL,
+        is_victory BOOLEAN,
+        created_at TIMESTAMP DEFAULT NOW(),
+
+        PRIMARY KEY (id, battle_time),
+        CONSTRAINT unique_player_battle UNIQUE (player_tag, battle_time)
+    ) PARTITION BY RANGE (battle_time);
+
+    ALTER SEQUENCE battles_id_seq OWNED BY battles.id;
+
+    SELECT
+        COALESCE(MIN(battle_time)::DATE, CURRENT_DATE),
+        GREATEST(MAX(battle_time)::DATE, CURRENT_DATE + 7)
+    INTO first_day, last_day
+    FROM battles_legacy;
+    PERFORM ensure_battles_partitions(first_day, last_day);
+
+    INSERT INTO battles SELECT * FROM battles_legacy;
+    DROP TABLE battles_legacy;
+END;
+$$;
+
+-- Indexes are created on every partition
+CREATE INDEX IF NOT EXISTS idx_battles_time ON battles(battle_time);
+CREATE INDEX IF NOT EXISTS idx_battles_deck ON battles(deck_signature);
+CREATE INDEX IF NOT EXISTS idx_battles_player ON battles(player_tag);
+
+COMMENT ON TABLE battles IS 'Individual battle records from top 1000 players, partitioned by day (battles_pYYYYMMDD)';
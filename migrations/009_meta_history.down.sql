@@ -0,0 +1,5 @@
+-- Royal API Personnel - Long-term meta history (rollback)
+-- Version: 009
+
+DROP TABLE IF EXISTS card_daily_stats;
+DROP TABLE IF EXISTS deck_daily_stats;
@@ -0,0 +1,35 @@
+-- Royal API Personnel - Long-term meta history
+-- Version: 009
+-- Date: 2026-01-30
+
+-- Table: deck_daily_stats
+-- Per-deck daily aggregates, kept after the raw battles are purged
+CREATE TABLE IF NOT EXISTS deck_daily_stats (
+    day DATE NOT NULL,
+    deck_signature VARCHAR(255) NOT NULL,
+    cards JSONB NOT NULL,
+    games INT NOT NULL DEFAULT 0,
+    wins INT NOT NULL DEFAULT 0,
+    losses INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (day, deck_signature)
+);
+
+CREATE INDEX IF NOT EXISTS idx_deck_daily_signature ON deck_daily_stats(deck_signature, day DESC);
+
+-- Table: card_daily_stats
+-- Per-card daily aggregates (a card is counted once per deck played)
+CREATE TABLE IF NOT EXISTS card_daily_stats (
+    day DATE NOT NULL,
+    card_id VARCHAR(20) NOT NULL,
+    card_name VARCHAR(100),
+    games INT NOT NULL DEFAULT 0,
+    wins INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (day, card_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_card_daily_card ON card_daily_stats(card_id, day DESC);
+
+COMMENT ON TABLE deck_daily_stats IS 'Permanent daily deck statistics rolled up from battles before retention';
+COMMENT ON TABLE card_daily_stats IS 'Permanent daily card statistics rolled up from battles before retention';
@@ -0,0 +1,6 @@
+-- Royal API Personnel - Balance patches (rollback)
+-- Version: 010
+
+DROP INDEX IF EXISTS idx_battles_patch;
+ALTER TABLE battles DROP COLUMN IF EXISTS patch_id;
+DROP TABLE IF EXISTS patches;
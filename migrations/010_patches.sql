@@ -0,0 +1,22 @@
+-- Royal API Personnel - Balance patches
+-- Version: 010
+-- Date: 2026-02-02
+
+-- Table: patches
+-- Balance changes, a battle belongs to the last patch released before it
+CREATE TABLE IF NOT EXISTS patches (
+    id SERIAL PRIMARY KEY,
+    released_at TIMESTAMP NOT NULL UNIQUE,
+    description TEXT,
+    affected_cards JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Added to every partition of battles
+ALTER TABLE battles ADD COLUMN IF NOT EXISTS patch_id INT;
+
+CREATE INDEX IF NOT EXISTS idx_battles_patch ON battles(patch_id, deck_signature);
+
+COMMENT ON TABLE patches IS 'Balance patches, managed with the /patches endpoints';
+COMMENT ON COLUMN patches.affected_cards IS 'JSON array of the card IDs changed by the patch';
+COMMENT ON COLUMN battles.patch_id IS 'Patch active at battle_time, NULL before the first known patch';
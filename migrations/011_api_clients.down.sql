@@ -0,0 +1,4 @@
+-- Royal API Personnel - API clients (rollback)
+-- Version: 011
+
+DROP TABLE IF EXISTS api_clients;
@@ -0,0 +1,19 @@
+-- Royal API Personnel - API clients
+-- Version: 011
+-- Date: 2026-02-09
+
+-- Table: api_clients
+-- Consumers of the REST API, each with its own token and scopes
+CREATE TABLE IF NOT EXISTS api_clients (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP DEFAULT NOW(),
+    expires_at TIMESTAMP,
+    revoked_at TIMESTAMP
+);
+
+COMMENT ON TABLE api_clients IS 'API consumers, managed with the /clients endpoints';
+COMMENT ON COLUMN api_clients.token_hash IS 'Hex SHA-256 of the token, the token itself is only returned on creation';
+COMMENT ON COLUMN api_clients.scopes IS 'Granted scopes: read:decks, read:stats, admin';
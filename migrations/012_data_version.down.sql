@@ -0,0 +1,8 @@
+-- Royal API Personnel - Data version for response caching (rollback)
+-- Version: 012
+
+DROP TRIGGER IF EXISTS collection_stats_data_version ON collection_stats;
+DROP TRIGGER IF EXISTS patches_data_version ON patches;
+DROP TRIGGER IF EXISTS meta_decks_data_version ON meta_decks;
+DROP FUNCTION IF EXISTS bump_data_version();
+DROP TABLE IF EXISTS data_version;
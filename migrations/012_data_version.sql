@@ -0,0 +1,41 @@
+-- Royal API Personnel - Data version for response caching
+-- Version: 012
+-- Date: 2026-02-16
+
+-- Table: data_version
+-- Single row bumped whenever data served by the read endpoints changes, the
+-- API compares it to invalidate its cached responses
+CREATE TABLE IF NOT EXISTS data_version (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    version BIGINT NOT NULL DEFAULT 1,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO data_version (id) VALUES (TRUE) ON CONFLICT (id) DO NOTHING;
+
+CREATE OR REPLACE FUNCTION bump_data_version()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE data_version SET version = version + 1, updated_at = NOW();
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Statement-level: a Recalculate or a patch tagging battles bumps once.
+-- battles is not watched, new battles are served after the Recalculate ending each collection.
+DROP TRIGGER IF EXISTS meta_decks_data_version ON meta_decks;
+CREATE TRIGGER meta_decks_data_version
+    AFTER INSERT OR UPDATE OR DELETE ON meta_decks
+    FOR EACH STATEMENT EXECUTE FUNCTION bump_data_version();
+
+DROP TRIGGER IF EXISTS patches_data_version ON patches;
+CREATE TRIGGER patches_data_version
+    AFTER INSERT OR UPDATE OR DELETE ON patches
+    FOR EACH STATEMENT EXECUTE FUNCTION bump_data_version();
+
+DROP TRIGGER IF EXISTS collection_stats_data_version ON collection_stats;
+CREATE TRIGGER collection_stats_data_version
+    AFTER INSERT OR UPDATE OR DELETE ON collection_stats
+    FOR EACH STATEMENT EXECUTE FUNCTION bump_data_version();
+
+COMMENT ON TABLE data_version IS 'Version of the served data, bumped by triggers on meta_decks, patches and collection_stats';
@@ -0,0 +1,6 @@
+-- Royal API Personnel - read:metrics scope of API clients (rollback)
+-- Version: 013
+
+ALTER TABLE api_clients DROP CONSTRAINT IF EXISTS api_clients_scopes_check;
+
+COMMENT ON COLUMN api_clients.scopes IS 'Granted scopes: read:decks, read:stats, admin';
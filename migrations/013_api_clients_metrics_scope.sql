@@ -0,0 +1,10 @@
+-- Royal API Personnel - read:metrics scope of API clients
+-- Version: 013
+-- Date: 2026-10-19
+
+-- Restrict the scopes to the ones checked by the API (models.Scopes)
+ALTER TABLE api_clients DROP CONSTRAINT IF EXISTS api_clients_scopes_check;
+ALTER TABLE api_clients ADD CONSTRAINT api_clients_scopes_check
+    CHECK (scopes <@ ARRAY['read:decks', 'read:stats', 'read:metrics', 'admin']::TEXT[]);
+
+COMMENT ON COLUMN api_clients.scopes IS 'Granted scopes: read:decks, read:stats, read:metrics, admin';
@@ -0,0 +1,8 @@
+-- Royal API Personnel - Bump the data version on completed collections only (rollback)
+-- Version: 014
+
+DROP TRIGGER IF EXISTS collection_stats_delete_data_version ON collection_stats;
+DROP TRIGGER IF EXISTS collection_stats_data_version ON collection_stats;
+CREATE TRIGGER collection_stats_data_version
+    AFTER INSERT OR UPDATE OR DELETE ON collection_stats
+    FOR EACH STATEMENT EXECUTE FUNCTION bump_data_version();
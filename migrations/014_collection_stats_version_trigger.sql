@@ -0,0 +1,18 @@
+-- Royal API Personnel - Bump the data version on completed collections only
+-- Version: 014
+-- Date: 2026-10-19
+
+-- The read endpoints only serve completed runs: checkpoints updating a running
+-- collection no longer invalidate the response cache. WHEN requires row-level
+-- triggers, a run completing in a single UPDATE bumps once.
+DROP TRIGGER IF EXISTS collection_stats_data_version ON collection_stats;
+CREATE TRIGGER collection_stats_data_version
+    AFTER UPDATE OF status ON collection_stats
+    FOR EACH ROW
+    WHEN (NEW.status = 'completed' AND OLD.status IS DISTINCT FROM NEW.status)
+    EXECUTE FUNCTION bump_data_version();
+
+DROP TRIGGER IF EXISTS collection_stats_delete_data_version ON collection_stats;
+CREATE TRIGGER collection_stats_delete_data_version
+    AFTER DELETE ON collection_stats
+    FOR EACH STATEMENT EXECUTE FUNCTION bump_data_version();
@@ -0,0 +1,5 @@
+-- Royal API Personnel - Describe gap detection against the last battle seen (rollback)
+-- Version: 015
+
+COMMENT ON COLUMN collection_stats.players_checked IS 'Players fetched that already had stored battles (gap detection possible)';
+COMMENT ON COLUMN collection_stats.players_with_gaps IS 'Players whose oldest fetched battle is newer than their latest stored battle';
@@ -0,0 +1,9 @@
+-- Royal API Personnel - Describe gap detection against the last battle seen
+-- Version: 015
+-- Date: 2026-10-19
+
+-- Gaps are detected against the latest battle of any mode seen from the API at
+-- the previous fetch (player_schedule.last_battle_time), not against the
+-- battles stored, which only hold PvP ladder battles
+COMMENT ON COLUMN collection_stats.players_checked IS 'Players fetched before, whose previous fetch allows gap detection';
+COMMENT ON COLUMN collection_stats.players_with_gaps IS 'Players whose oldest fetched battle is newer than the latest battle seen at their previous fetch';
@@ -0,0 +1,4 @@
+-- Royal API Personnel - Count battles without partition as skipped (rollback)
+-- Version: 016
+
+COMMENT ON COLUMN collection_stats.battles_skipped IS 'Parsed battles dropped because their cards could not be encoded';
@@ -0,0 +1,7 @@
+-- Royal API Personnel - Count battles without partition as skipped
+-- Version: 016
+-- Date: 2026-10-19
+
+-- Inserts no longer create partitions: the collector creates them ahead once a
+-- day, and battles of a day without partition are skipped
+COMMENT ON COLUMN collection_stats.battles_skipped IS 'Parsed battles dropped because their cards could not be encoded or their day has no partition';
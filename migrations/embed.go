@@ -0,0 +1,10 @@
+// Package migrations embeds the SQL migrations so that the binary can
+// bootstrap its own schema without shipping this directory.
+package migrations
+
+import "embed"
+
+// FS holds the NNN_name.sql and NNN_name.down.sql files of this directory
+//
+//go:embed *.sql
+var FS embed.FS
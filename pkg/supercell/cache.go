@@ -0,0 +1,264 @@
+package supercell
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Supported cache stores
+const (
+	CacheNone   = "none"
+	CacheMemory = "memory"
+	CacheDisk   = "disk"
+)
+
+// CacheEntry is a cached API response with its validators
+type CacheEntry struct {
+	Body         []byte    `json:"body"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	Expires      time.Time `json:"expires"`
+}
+
+// Fresh reports whether the entry can be served without contacting the API
+func (e *CacheEntry) Fresh(now time.Time) bool {
+	return now.Before(e.Expires)
+}
+
+// DefaultCacheEntries bounds the caches created by NewCache, enough for the
+// battlelogs of the 1000 tracked players and the rankings
+const DefaultCacheEntries = 2000
+
+// Cache stores API responses keyed by request URL
+type Cache interface {
+	Get(key string) (*CacheEntry, bool)
+	Set(key string, entry *CacheEntry) error
+}
+
+// NewCache creates the cache store matching kind (none, memory or disk).
+// It returns a nil Cache for "none", which disables caching.
+func NewCache(kind, dir string) (Cache, error) {
+	switch kind {
+	case "", CacheNone:
+		return nil, nil
+	case CacheMemory:
+		return NewMemoryCache(DefaultCacheEntries), nil
+	case CacheDisk:
+		if dir == "" {
+			return nil, fmt.Errorf("cache directory is required for %q cache", CacheDisk)
+		}
+		cache, err := NewDiskCache(dir, DefaultCacheEntries)
+		if err != nil {
+			return nil, err
+		}
+		return cache, nil
+	default:
+		return nil, fmt.Errorf("unknown cache store %q", kind)
+	}
+}
+
+// MemoryCache is an in-process Cache holding at most maxEntries responses
+type MemoryCache struct {
+	mu         sync.RWMutex
+	entries    map[string]*CacheEntry
+	maxEntries int
+}
+
+var _ Cache = (*MemoryCache)(nil)
+
+// NewMemoryCache creates an empty in-memory cache of maxEntries responses
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	return &MemoryCache{
+		entries:    make(map[string]*CacheEntry),
+		maxEntries: maxEntries,
+	}
+}
+
+// Get returns the entry stored for key
+func (c *MemoryCache) Get(key string) (*CacheEntry, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := c.entries[key]
+	return entry, ok
+}
+
+// Set stores entry for key. When the cache is full, expired entries are
+// evicted first, then the entry expiring first.
+func (c *MemoryCache) Set(key string, entry *CacheEntry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.maxEntries {
+		c.evict(time.Now())
+	}
+	c.entries[key] = entry
+	return nil
+}
+
+// evict makes room for one entry
+func (c *MemoryCache) evict(now time.Time) {
+	var oldestKey string
+	var oldest time.Time
+	for key, entry := range c.entries {
+		if !entry.Fresh(now) {
+			delete(c.entries, key)
+			continue
+		}
+		if oldestKey == "" || entry.Expires.Before(oldest) {
+			oldestKey, oldest = key, entry.Expires
+		}
+	}
+	if len(c.entries) >= c.maxEntries && oldestKey != "" {
+		delete(c.entries, oldestKey)
+	}
+}
+
+// DiskCache persists entries as JSON files, so the cache survives between collect
+// runs. It holds at most maxEntries responses.
+type DiskCache struct {
+	mu         sync.Mutex
+	dir        string
+	entries    int
+	maxEntries int
+}
+
+var _ Cache = (*DiskCache)(nil)
+
+// NewDiskCache creates a cache storing at most maxEntries entries under dir
+func NewDiskCache(dir string, maxEntries int) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cache entries: %w", err)
+	}
+	return &DiskCache{dir: dir, entries: len(files), maxEntries: maxEntries}, nil
+}
+
+// Get returns the entry stored for key. Unreadable entries are treated as misses.
+func (c *DiskCache) Get(key string) (*CacheEntry, bool) {
+	return readEntry(c.path(key))
+}
+
+// readEntry decodes the cache entry stored in file
+func readEntry(file string) (*CacheEntry, bool) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, false
+	}
+
+	var entry CacheEntry
+	if err := json.Unmarshal(content, &entry); err != nil {
+		return nil, false
+	}
+	return &entry, true
+}
+
+// Set stores entry for key, replacing the file atomically. When the cache is
+// full, expired entries are evicted first, then the entry expiring first.
+func (c *DiskCache) Set(key string, entry *CacheEntry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, err := os.Stat(c.path(key))
+	added := os.IsNotExist(err)
+	if added && c.entries >= c.maxEntries {
+		c.evict(time.Now())
+	}
+
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(c.dir, ".entry-*")
+	if err != nil {
+		return fmt.Errorf("failed to create cache entry: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		return fmt.Errorf("failed to store cache entry: %w", err)
+	}
+	if added {
+		c.entries++
+	}
+	return nil
+}
+
+// evict makes room for one entry. Unreadable entries are removed like expired ones.
+func (c *DiskCache) evict(now time.Time) {
+	files, err := filepath.Glob(filepath.Join(c.dir, "*.json"))
+	if err != nil {
+		return
+	}
+	c.entries = len(files)
+
+	var oldestFile string
+	var oldest time.Time
+	for _, file := range files {
+		entry, ok := readEntry(file)
+		if !ok || !entry.Fresh(now) {
+			if os.Remove(file) == nil {
+				c.entries--
+			}
+			continue
+		}
+		if oldestFile == "" || entry.Expires.Before(oldest) {
+			oldestFile, oldest = file, entry.Expires
+		}
+	}
+	if c.entries >= c.maxEntries && oldestFile != "" {
+		if os.Remove(oldestFile) == nil {
+			c.entries--
+		}
+	}
+}
+
+func (c *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// cacheLifetime returns how long a response may be served from cache according
+// to its Cache-Control header, and false when it must not be stored at all
+func cacheLifetime(header http.Header) (time.Duration, bool) {
+	var maxAge time.Duration
+	noCache := false
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		switch {
+		case directive == "no-store":
+			return 0, false
+		case directive == "no-cache":
+			noCache = true
+		case strings.HasPrefix(directive, "max-age="):
+			seconds, err := strconv.Atoi(strings.TrimPrefix(directive, "max-age="))
+			if err == nil && seconds > 0 {
+				maxAge = time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	if noCache {
+		return 0, true
+	}
+	return maxAge, true
+}
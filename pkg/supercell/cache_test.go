@@ -0,0 +1,204 @@
+package supercell
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHTTPClient_CacheServesFreshResponses(t *testing.T) {
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`[{"type": "PvP"}]`))
+	}))
+	defer server.Close()
+
+	client := NewCachedClient("test_token", server.URL+"/v1", NewMemoryCache(10))
+
+	for i := 0; i < 3; i++ {
+		battles, err := client.GetBattlelog(context.Background(), "#2PP")
+		if err != nil {
+			t.Fatalf("GetBattlelog() error = %v", err)
+		}
+		if len(battles) != 1 {
+			t.Fatalf("expected 1 battle, got %d", len(battles))
+		}
+	}
+
+	if hits != 1 {
+		t.Errorf("expected 1 request while cached response is fresh, got %d", hits)
+	}
+}
+
+func TestHTTPClient_CacheRevalidatesWithETag(t *testing.T) {
+	hits := 0
+	conditional := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Cache-Control", "max-age=0")
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			conditional++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`[{"type": "PvP"}, {"type": "PvP"}]`))
+	}))
+	defer server.Close()
+
+	client := NewCachedClient("test_token", server.URL+"/v1", NewMemoryCache(10))
+
+	for i := 0; i < 2; i++ {
+		battles, err := client.GetBattlelog(context.Background(), "#2PP")
+		if err != nil {
+			t.Fatalf("GetBattlelog() error = %v", err)
+		}
+		if len(battles) != 2 {
+			t.Fatalf("expected 2 battles, got %d", len(battles))
+		}
+	}
+
+	if hits != 2 || conditional != 1 {
+		t.Errorf("expected 2 requests including 1 conditional, got %d/%d", hits, conditional)
+	}
+}
+
+func TestHTTPClient_CacheHonorsNoStore(t *testing.T) {
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Cache-Control", "no-store, max-age=60")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client := NewCachedClient("test_token", server.URL+"/v1", NewMemoryCache(10))
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetBattlelog(context.Background(), "#2PP"); err != nil {
+			t.Fatalf("GetBattlelog() error = %v", err)
+		}
+	}
+
+	if hits != 2 {
+		t.Errorf("expected no-store responses to bypass cache, got %d requests", hits)
+	}
+}
+
+func TestDiskCache_RoundTrip(t *testing.T) {
+	cache, err := NewDiskCache(t.TempDir(), 10)
+	if err != nil {
+		t.Fatalf("NewDiskCache() error = %v", err)
+	}
+
+	if _, ok := cache.Get("missing"); ok {
+		t.Error("expected miss for unknown key")
+	}
+
+	entry := &CacheEntry{
+		Body:    []byte(`{"items": []}`),
+		ETag:    `"abc"`,
+		Expires: time.Now().Add(time.Minute).Round(time.Second),
+	}
+	if err := cache.Set("key", entry); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	got, ok := cache.Get("key")
+	if !ok {
+		t.Fatal("expected hit after Set")
+	}
+	if string(got.Body) != string(entry.Body) || got.ETag != entry.ETag || !got.Expires.Equal(entry.Expires) {
+		t.Errorf("Get() = %+v, want %+v", got, entry)
+	}
+}
+
+func TestCacheLifetime(t *testing.T) {
+	tests := []struct {
+		header    string
+		wantAge   time.Duration
+		wantStore bool
+	}{
+		{"max-age=120", 120 * time.Second, true},
+		{"public, max-age=30", 30 * time.Second, true},
+		{"no-cache, max-age=30", 0, true},
+		{"no-store", 0, false},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		header := http.Header{}
+		header.Set("Cache-Control", tt.header)
+		age, store := cacheLifetime(header)
+		if age != tt.wantAge || store != tt.wantStore {
+			t.Errorf("cacheLifetime(%q) = %v, %v, want %v, %v", tt.header, age, store, tt.wantAge, tt.wantStore)
+		}
+	}
+}
+
+func TestMemoryCache_Bounded(t *testing.T) {
+	cache := NewMemoryCache(2)
+	now := time.Now()
+
+	cache.Set("expired", &CacheEntry{Expires: now.Add(-time.Minute)})
+	cache.Set("soon", &CacheEntry{Expires: now.Add(time.Minute)})
+	cache.Set("later", &CacheEntry{Expires: now.Add(time.Hour)})
+
+	if _, ok := cache.Get("expired"); ok {
+		t.Error("expired entry kept over a fresh one")
+	}
+	if _, ok := cache.Get("later"); !ok {
+		t.Fatal("new entry not stored")
+	}
+
+	cache.Set("latest", &CacheEntry{Expires: now.Add(2 * time.Hour)})
+	if _, ok := cache.Get("soon"); ok {
+		t.Error("entry expiring first not evicted")
+	}
+	if len(cache.entries) != 2 {
+		t.Errorf("entries = %d, want 2", len(cache.entries))
+	}
+}
+
+func TestDiskCache_Bounded(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewDiskCache(dir, 2)
+	if err != nil {
+		t.Fatalf("NewDiskCache() error = %v", err)
+	}
+	now := time.Now()
+
+	cache.Set("expired", &CacheEntry{Expires: now.Add(-time.Minute)})
+	cache.Set("soon", &CacheEntry{Expires: now.Add(time.Minute)})
+	cache.Set("later", &CacheEntry{Expires: now.Add(time.Hour)})
+
+	if _, ok := cache.Get("expired"); ok {
+		t.Error("expired entry kept over a fresh one")
+	}
+	if _, ok := cache.Get("later"); !ok {
+		t.Fatal("new entry not stored")
+	}
+
+	// The entries stored by a previous run count towards the bound
+	reopened, err := NewDiskCache(dir, 2)
+	if err != nil {
+		t.Fatalf("NewDiskCache() error = %v", err)
+	}
+	reopened.Set("latest", &CacheEntry{Expires: now.Add(2 * time.Hour)})
+	if _, ok := reopened.Get("soon"); ok {
+		t.Error("entry expiring first not evicted")
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 2 {
+		t.Errorf("files = %d, want 2", len(files))
+	}
+}
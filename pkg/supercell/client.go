@@ -15,26 +15,54 @@ import (
 	"github.com/leopoldhub/royal-api-personal/internal/errors"
 )
 
+const (
+	// DefaultBaseURL is the official Supercell API endpoint
+	DefaultBaseURL = "https://api.clashroyale.com/v1"
+
+	// RoyaleAPIProxyBaseURL is the RoyaleAPI proxy, which forwards requests from a
+	// static IP so that hosts with a dynamic IP can use a key whitelisted for the proxy
+	RoyaleAPIProxyBaseURL = "https://proxy.royaleapi.dev/v1"
+)
+
 // HTTPClient implements the Client interface
 type HTTPClient struct {
 	apiKey     string
 	baseURL    string
 	httpClient *http.Client
+	cache      Cache
 }
 
 var _ Client = (*HTTPClient)(nil)
 
 // NewClient creates a new Supercell API client
 func NewClient(apiKey string) Client {
+	return NewClientWithBaseURL(apiKey, DefaultBaseURL)
+}
+
+// NewClientWithBaseURL creates a Supercell API client targeting a custom base URL
+// (e.g. RoyaleAPIProxyBaseURL). An empty baseURL falls back to DefaultBaseURL.
+func NewClientWithBaseURL(apiKey, baseURL string) Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
 	return &HTTPClient{
 		apiKey:  apiKey,
-		baseURL: "https://api.clashroyale.com/v1",
+		baseURL: strings.TrimSuffix(baseURL, "/"),
 		httpClient: &http.Client{
 			Timeout: 15 * time.Second,
 		},
 	}
 }
 
+// NewCachedClient creates a Supercell API client that stores responses in cache,
+// serving them while fresh (Cache-Control max-age) and revalidating them with
+// If-None-Match / If-Modified-Since once stale
+func NewCachedClient(apiKey, baseURL string, cache Cache) Client {
+	client := NewClientWithBaseURL(apiKey, baseURL).(*HTTPClient)
+	client.cache = cache
+	return client
+}
+
 // GetTopPlayers retrieves top N players from global rankings
 func (c *HTTPClient) GetTopPlayers(ctx context.Context, limit int) ([]Player, error) {
 	endpoint := fmt.Sprintf("/locations/global/rankings/players?limit=%d", limit)
@@ -43,7 +71,7 @@ func (c *HTTPClient) GetTopPlayers(ctx context.Context, limit int) ([]Player, er
 		Items []Player `json:"items"`
 	}
 
-	if err := c.doRequest(ctx, endpoint, &response); err != nil {
+	if err := c.doRequest(ctx, "rankings", endpoint, &response); err != nil {
 		return nil, err
 	}
 
@@ -59,17 +87,29 @@ func (c *HTTPClient) GetBattlelog(ctx context.Context, tag string) ([]BattleRaw,
 	// L'API retourne un array direct, pas un objet {"items": [...]}
 	var battles []BattleRaw
 
-	if err := c.doRequest(ctx, endpoint, &battles); err != nil {
+	if err := c.doRequest(ctx, "battlelog", endpoint, &battles); err != nil {
 		return nil, err
 	}
 
 	return battles, nil
 }
 
-// doRequest performs HTTP request with retry logic
-func (c *HTTPClient) doRequest(ctx context.Context, endpoint string, result interface{}) error {
+// doRequest performs HTTP request with retry logic, each attempt being recorded
+// in the metrics of name
+func (c *HTTPClient) doRequest(ctx context.Context, name, endpoint string, result interface{}) error {
 	const maxRetries = 3
 
+	cacheKey := c.baseURL + endpoint
+	var cached *CacheEntry
+	if c.cache != nil {
+		if entry, ok := c.cache.Get(cacheKey); ok {
+			if entry.Fresh(time.Now()) {
+				return decodeBody(entry.Body, result)
+			}
+			cached = entry
+		}
+	}
+
 	for attempt := 0; attempt < maxRetries; attempt++ {
 		req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+endpoint, nil)
 		if err != nil {
@@ -78,9 +118,19 @@ func (c *HTTPClient) doRequest(ctx context.Context, endpoint string, result inte
 
 		req.Header.Set("Authorization", "Bearer "+c.apiKey)
 		req.Header.Set("Accept", "application/json")
+		if cached != nil {
+			if cached.ETag != "" {
+				req.Header.Set("If-None-Match", cached.ETag)
+			}
+			if cached.LastModified != "" {
+				req.Header.Set("If-Modified-Since", cached.LastModified)
+			}
+		}
 
+		start := time.Now()
 		resp, err := c.httpClient.Do(req)
 		if err != nil {
+			observeRequest(name, 0, start)
 			if attempt < maxRetries-1 {
 				time.Sleep(time.Duration(math.Pow(2, float64(attempt))) * time.Second)
 				continue
@@ -90,10 +140,20 @@ func (c *HTTPClient) doRequest(ctx context.Context, endpoint string, result inte
 		defer resp.Body.Close()
 
 		body, err := io.ReadAll(resp.Body)
+		observeRequest(name, resp.StatusCode, start)
 		if err != nil {
 			return fmt.Errorf("failed to read response body: %w", err)
 		}
 
+		if resp.StatusCode == http.StatusNotModified && cached != nil {
+			if maxAge, ok := cacheLifetime(resp.Header); ok {
+				refreshed := *cached
+				refreshed.Expires = time.Now().Add(maxAge)
+				c.storeCache(cacheKey, &refreshed)
+			}
+			return decodeBody(cached.Body, result)
+		}
+
 		if resp.StatusCode == http.StatusTooManyRequests {
 			retryAfter := c.parseRetryAfter(resp.Header.Get("Retry-After"))
 			if attempt < maxRetries-1 {
@@ -147,8 +207,19 @@ func (c *HTTPClient) doRequest(ctx context.Context, endpoint string, result inte
 			}
 		}
 
-		if err := json.Unmarshal(body, result); err != nil {
-			return fmt.Errorf("failed to decode response: %w", err)
+		if err := decodeBody(body, result); err != nil {
+			return err
+		}
+
+		if c.cache != nil {
+			if maxAge, ok := cacheLifetime(resp.Header); ok {
+				c.storeCache(cacheKey, &CacheEntry{
+					Body:         body,
+					ETag:         resp.Header.Get("ETag"),
+					LastModified: resp.Header.Get("Last-Modified"),
+					Expires:      time.Now().Add(maxAge),
+				})
+			}
 		}
 
 		return nil
@@ -157,6 +228,18 @@ func (c *HTTPClient) doRequest(ctx context.Context, endpoint string, result inte
 	return fmt.Errorf("max retries exceeded")
 }
 
+// storeCache saves an entry, a failing cache never fails the request
+func (c *HTTPClient) storeCache(key string, entry *CacheEntry) {
+	_ = c.cache.Set(key, entry)
+}
+
+func decodeBody(body []byte, result interface{}) error {
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
 // parseRetryAfter parses the Retry-After header value
 func (c *HTTPClient) parseRetryAfter(value string) int {
 	if value == "" {
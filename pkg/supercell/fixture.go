@@ -0,0 +1,157 @@
+package supercell
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/leopoldhub/royal-api-personal/internal/errors"
+)
+
+// Fixture directory layout (same JSON shapes as the real API):
+//
+//	<dir>/top_players.json         {"items": [...]}
+//	<dir>/battlelogs/<TAG>.json    [...] (tag without the leading #)
+const (
+	fixtureTopPlayersFile = "top_players.json"
+	fixtureBattlelogsDir  = "battlelogs"
+)
+
+// FixtureClient implements the Client interface by replaying recorded JSON from disk
+type FixtureClient struct {
+	dir string
+}
+
+var _ Client = (*FixtureClient)(nil)
+
+// NewFixtureClient creates a client that serves recorded responses from dir
+func NewFixtureClient(dir string) Client {
+	return &FixtureClient{dir: dir}
+}
+
+// GetTopPlayers returns the first limit players of the recorded rankings
+func (c *FixtureClient) GetTopPlayers(ctx context.Context, limit int) ([]Player, error) {
+	var response struct {
+		Items []Player `json:"items"`
+	}
+
+	if err := c.load(ctx, fixtureTopPlayersFile, &response); err != nil {
+		return nil, err
+	}
+
+	if limit > 0 && len(response.Items) > limit {
+		response.Items = response.Items[:limit]
+	}
+
+	return response.Items, nil
+}
+
+// GetBattlelog returns the recorded battlelog of a player.
+// A missing fixture behaves like an unknown player (404 APIError).
+func (c *FixtureClient) GetBattlelog(ctx context.Context, tag string) ([]BattleRaw, error) {
+	var battles []BattleRaw
+
+	if err := c.load(ctx, BattlelogFixturePath(tag), &battles); err != nil {
+		return nil, err
+	}
+
+	return battles, nil
+}
+
+func (c *FixtureClient) load(ctx context.Context, name string, result interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(filepath.Join(c.dir, name))
+	if os.IsNotExist(err) {
+		return &errors.APIError{
+			StatusCode: http.StatusNotFound,
+			Message:    "fixture not found",
+			Endpoint:   name,
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read fixture %s: %w", name, err)
+	}
+
+	if err := json.Unmarshal(content, result); err != nil {
+		return fmt.Errorf("failed to decode fixture %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// BattlelogFixturePath returns the path of a player's battlelog relative to a fixture directory
+func BattlelogFixturePath(tag string) string {
+	name := strings.ToUpper(strings.TrimPrefix(tag, "#"))
+	return filepath.Join(fixtureBattlelogsDir, name+".json")
+}
+
+// RecordingClient wraps a Client and writes every successful response to a
+// fixture directory, so that a live collection can later be replayed with FixtureClient
+type RecordingClient struct {
+	next Client
+	dir  string
+}
+
+var _ Client = (*RecordingClient)(nil)
+
+// NewRecordingClient creates a client recording responses of next into dir
+func NewRecordingClient(next Client, dir string) Client {
+	return &RecordingClient{next: next, dir: dir}
+}
+
+// GetTopPlayers forwards the call and records the rankings
+func (c *RecordingClient) GetTopPlayers(ctx context.Context, limit int) ([]Player, error) {
+	players, err := c.next.GetTopPlayers(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	response := struct {
+		Items []Player `json:"items"`
+	}{Items: players}
+
+	if err := c.save(fixtureTopPlayersFile, response); err != nil {
+		return nil, err
+	}
+
+	return players, nil
+}
+
+// GetBattlelog forwards the call and records the battlelog
+func (c *RecordingClient) GetBattlelog(ctx context.Context, tag string) ([]BattleRaw, error) {
+	battles, err := c.next.GetBattlelog(ctx, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.save(BattlelogFixturePath(tag), battles); err != nil {
+		return nil, err
+	}
+
+	return battles, nil
+}
+
+func (c *RecordingClient) save(name string, value interface{}) error {
+	path := filepath.Join(c.dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create fixture directory: %w", err)
+	}
+
+	content, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode fixture %s: %w", name, err)
+	}
+
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		return fmt.Errorf("failed to write fixture %s: %w", name, err)
+	}
+
+	return nil
+}
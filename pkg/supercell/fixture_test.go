@@ -0,0 +1,133 @@
+package supercell
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/leopoldhub/royal-api-personal/internal/errors"
+)
+
+type stubClient struct {
+	players  []Player
+	battles  map[string][]BattleRaw
+	requests int
+}
+
+func (s *stubClient) GetTopPlayers(ctx context.Context, limit int) ([]Player, error) {
+	s.requests++
+	return s.players, nil
+}
+
+func (s *stubClient) GetBattlelog(ctx context.Context, tag string) ([]BattleRaw, error) {
+	s.requests++
+	battles, ok := s.battles[tag]
+	if !ok {
+		return nil, &errors.APIError{StatusCode: 404, Message: "resource not found"}
+	}
+	return battles, nil
+}
+
+func TestFixtureClient_GetBattlelog(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "battlelogs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	content := `[{"type": "PvP", "battleTime": "20240110T201530.000Z", "gameMode": {"id": 72000006, "name": "Ladder"}}]`
+	if err := os.WriteFile(filepath.Join(dir, "battlelogs", "2PP.json"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	client := NewFixtureClient(dir)
+
+	battles, err := client.GetBattlelog(context.Background(), "#2PP")
+	if err != nil {
+		t.Fatalf("GetBattlelog() error = %v", err)
+	}
+	if len(battles) != 1 || battles[0].Type != "PvP" {
+		t.Errorf("unexpected battles: %+v", battles)
+	}
+
+	_, err = client.GetBattlelog(context.Background(), "#UNKNOWN")
+	apiErr, ok := err.(*errors.APIError)
+	if !ok || !apiErr.IsNotFound() {
+		t.Errorf("expected not found APIError for missing fixture, got %v", err)
+	}
+}
+
+func TestRecordingClient_ReplayWithFixtureClient(t *testing.T) {
+	dir := t.TempDir()
+	live := &stubClient{
+		players: []Player{{Tag: "#2PP", Rank: 1}, {Tag: "#ABC", Rank: 2}},
+		battles: map[string][]BattleRaw{
+			"#2PP": {{Type: "PvP", BattleTime: "20240110T201530.000Z"}},
+		},
+	}
+
+	recorder := NewRecordingClient(live, dir)
+	ctx := context.Background()
+
+	if _, err := recorder.GetTopPlayers(ctx, 10); err != nil {
+		t.Fatalf("GetTopPlayers() error = %v", err)
+	}
+	if _, err := recorder.GetBattlelog(ctx, "#2PP"); err != nil {
+		t.Fatalf("GetBattlelog() error = %v", err)
+	}
+	if _, err := recorder.GetBattlelog(ctx, "#ABC"); err == nil {
+		t.Fatal("expected error to be forwarded")
+	}
+
+	replay := NewFixtureClient(dir)
+
+	players, err := replay.GetTopPlayers(ctx, 1)
+	if err != nil {
+		t.Fatalf("replay GetTopPlayers() error = %v", err)
+	}
+	if len(players) != 1 || players[0].Tag != "#2PP" {
+		t.Errorf("expected limit to be applied on replay, got %+v", players)
+	}
+
+	battles, err := replay.GetBattlelog(ctx, "#2PP")
+	if err != nil {
+		t.Fatalf("replay GetBattlelog() error = %v", err)
+	}
+	if len(battles) != 1 || battles[0].BattleTime != "20240110T201530.000Z" {
+		t.Errorf("unexpected replayed battles: %+v", battles)
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        Options
+		wantBaseURL string
+		wantErr     bool
+	}{
+		{name: "default", opts: Options{APIKey: "key"}, wantBaseURL: DefaultBaseURL},
+		{name: "royaleapi", opts: Options{Source: SourceRoyaleAPI, APIKey: "key"}, wantBaseURL: RoyaleAPIProxyBaseURL},
+		{name: "custom base url", opts: Options{Source: SourceAPI, BaseURL: "http://localhost:9000/v1/"}, wantBaseURL: "http://localhost:9000/v1"},
+		{name: "fixtures", opts: Options{Source: SourceFixtures, FixturesDir: "testdata"}},
+		{name: "fixtures without dir", opts: Options{Source: SourceFixtures}, wantErr: true},
+		{name: "unknown", opts: Options{Source: "scraper"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := New(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantBaseURL == "" {
+				return
+			}
+			httpClient, ok := client.(*HTTPClient)
+			if !ok {
+				t.Fatalf("expected *HTTPClient, got %T", client)
+			}
+			if httpClient.baseURL != tt.wantBaseURL {
+				t.Errorf("baseURL = %s, want %s", httpClient.baseURL, tt.wantBaseURL)
+			}
+		})
+	}
+}
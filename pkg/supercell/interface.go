@@ -2,6 +2,7 @@ package supercell
 
 import (
 	"context"
+	"encoding/json"
 )
 
 // Client defines operations to fetch data from Supercell API
@@ -31,6 +32,31 @@ type BattleRaw struct {
 	GameMode   GameMode     `json:"gameMode"`
 	Team       []TeamMember `json:"team"`
 	Opponent   []TeamMember `json:"opponent"`
+
+	// Raw keeps the original payload, including fields not modeled above
+	Raw json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON decodes a battle and keeps a copy of the original payload in Raw
+func (b *BattleRaw) UnmarshalJSON(data []byte) error {
+	type battleRaw BattleRaw
+	var decoded battleRaw
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*b = BattleRaw(decoded)
+	b.Raw = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+// MarshalJSON returns the original payload when available, so that recorded
+// battles are stored exactly as received
+func (b BattleRaw) MarshalJSON() ([]byte, error) {
+	if len(b.Raw) > 0 {
+		return b.Raw, nil
+	}
+	type battleRaw BattleRaw
+	return json.Marshal(battleRaw(b))
 }
 
 // GameMode represents the game mode information
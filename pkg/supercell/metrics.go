@@ -0,0 +1,27 @@
+package supercell
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/leopoldhub/royal-api-personal/internal/metrics"
+)
+
+// Calls of HTTPClient, fresh cache hits excluded since they reach no server
+var (
+	apiRequests = metrics.Default.Counter("royal_supercell_requests_total",
+		"Supercell API calls by endpoint and status code, error when no response was received",
+		"endpoint", "status")
+	apiDuration = metrics.Default.Histogram("royal_supercell_request_duration_seconds",
+		"Supercell API call latency by endpoint", metrics.DefaultBuckets, "endpoint")
+)
+
+// observeRequest records a call to endpoint, status 0 standing for a transport error
+func observeRequest(endpoint string, status int, start time.Time) {
+	label := "error"
+	if status != 0 {
+		label = strconv.Itoa(status)
+	}
+	apiRequests.Inc(endpoint, label)
+	apiDuration.Observe(time.Since(start).Seconds(), endpoint)
+}
@@ -0,0 +1,40 @@
+package supercell
+
+import "fmt"
+
+// Supported data sources
+const (
+	SourceAPI       = "api"
+	SourceRoyaleAPI = "royaleapi"
+	SourceFixtures  = "fixtures"
+)
+
+// Options selects and configures the Client implementation
+type Options struct {
+	Source      string // api (default), royaleapi or fixtures
+	APIKey      string
+	BaseURL     string // overrides the source default for api/royaleapi
+	FixturesDir string // required for fixtures
+	Cache       Cache  // optional response cache for api/royaleapi
+}
+
+// New creates the Client matching opts.Source
+func New(opts Options) (Client, error) {
+	switch opts.Source {
+	case "", SourceAPI:
+		return NewCachedClient(opts.APIKey, opts.BaseURL, opts.Cache), nil
+	case SourceRoyaleAPI:
+		baseURL := opts.BaseURL
+		if baseURL == "" {
+			baseURL = RoyaleAPIProxyBaseURL
+		}
+		return NewCachedClient(opts.APIKey, baseURL, opts.Cache), nil
+	case SourceFixtures:
+		if opts.FixturesDir == "" {
+			return nil, fmt.Errorf("fixtures directory is required for source %q", SourceFixtures)
+		}
+		return NewFixtureClient(opts.FixturesDir), nil
+	default:
+		return nil, fmt.Errorf("unknown supercell source %q", opts.Source)
+	}
+}
@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/leopoldhub/royal-api-personal/internal/models"
+)
+
+// DefaultMinTrendScore is the z-score above which a usage growth is significant (p < 0.025, one-sided)
+const DefaultMinTrendScore = 1.96
+
+// TrendOptions controls RankTrendingDecks
+type TrendOptions struct {
+	MinScore      float64   // minimum z-score of the usage growth
+	EmergingSince time.Time // decks first seen after this time are flagged as emerging
+}
+
+// RankTrendingDecks scores each deck with a two-proportion z-test of its usage share
+// in the recent window against the baseline window, keeps the significant growths and
+// returns them by decreasing score
+func RankTrendingDecks(stats []*models.DeckWindowStats, opts TrendOptions) []*models.TrendingDeck {
+	trending := make([]*models.TrendingDeck, 0)
+	for _, deck := range stats {
+		score, ok := usageGrowthScore(deck)
+		if !ok || score < opts.MinScore {
+			continue
+		}
+
+		trending = append(trending, &models.TrendingDeck{
+			Signature:       deck.Signature,
+			Cards:           deck.Cards,
+			RecentGames:     deck.RecentGames,
+			BaselineGames:   deck.BaselineGames,
+			RecentUsage:     percent(deck.RecentGames, deck.RecentTotal),
+			BaselineUsage:   percent(deck.BaselineGames, deck.BaselineTotal),
+			RecentWinRate:   percent(deck.RecentWins, deck.RecentGames),
+			BaselineWinRate: percent(deck.BaselineWins, deck.BaselineGames),
+			Score:           math.Round(score*100) / 100,
+			Emerging:        !opts.EmergingSince.IsZero() && !deck.FirstSeen.Before(opts.EmergingSince),
+			FirstSeen:       deck.FirstSeen,
+		})
+	}
+
+	sort.SliceStable(trending, func(i, j int) bool {
+		return trending[i].Score > trending[j].Score
+	})
+	return trending
+}
+
+// usageGrowthScore returns the pooled two-proportion z-score of the recent usage
+// share against the baseline one, false when a window has no games
+func usageGrowthScore(deck *models.DeckWindowStats) (float64, bool) {
+	if deck.RecentTotal == 0 || deck.BaselineTotal == 0 {
+		return 0, false
+	}
+
+	n1, n0 := float64(deck.RecentTotal), float64(deck.BaselineTotal)
+	p1 := float64(deck.RecentGames) / n1
+	p0 := float64(deck.BaselineGames) / n0
+	pooled := float64(deck.RecentGames+deck.BaselineGames) / (n1 + n0)
+
+	stderr := math.Sqrt(pooled * (1 - pooled) * (1/n1 + 1/n0))
+	if stderr == 0 {
+		return 0, false
+	}
+	return (p1 - p0) / stderr, true
+}
+
+func percent(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(total)*10000) / 100
+}
@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leopoldhub/royal-api-personal/internal/models"
+)
+
+func TestRankTrendingDecks(t *testing.T) {
+	now := time.Date(2026, 1, 20, 12, 0, 0, 0, time.UTC)
+	recentFrom := now.Add(-48 * time.Hour)
+
+	stats := []*models.DeckWindowStats{
+		{
+			// Usage share 2% -> 10%: strong growth
+			Signature: "rising", RecentGames: 100, BaselineGames: 50,
+			RecentTotal: 1000, BaselineTotal: 2500, FirstSeen: now.Add(-7 * 24 * time.Hour),
+		},
+		{
+			// Stable usage share of 5%
+			Signature: "stable", RecentGames: 50, BaselineGames: 125,
+			RecentTotal: 1000, BaselineTotal: 2500, FirstSeen: now.Add(-7 * 24 * time.Hour),
+		},
+		{
+			// Never played before the recent window
+			Signature: "emerging", RecentGames: 30, RecentWins: 20, BaselineGames: 0,
+			RecentTotal: 1000, BaselineTotal: 2500, FirstSeen: now.Add(-24 * time.Hour),
+		},
+		{
+			// Declining usage
+			Signature: "falling", RecentGames: 10, BaselineGames: 250,
+			RecentTotal: 1000, BaselineTotal: 2500, FirstSeen: now.Add(-7 * 24 * time.Hour),
+		},
+		{
+			// Too few games to be significant
+			Signature: "noise", RecentGames: 2, BaselineGames: 3,
+			RecentTotal: 1000, BaselineTotal: 2500, FirstSeen: now.Add(-7 * 24 * time.Hour),
+		},
+	}
+
+	trending := RankTrendingDecks(stats, TrendOptions{
+		MinScore:      DefaultMinTrendScore,
+		EmergingSince: recentFrom,
+	})
+
+	if len(trending) != 2 {
+		t.Fatalf("expected 2 trending decks, got %d: %+v", len(trending), trending)
+	}
+	if trending[0].Signature != "rising" || trending[1].Signature != "emerging" {
+		t.Errorf("unexpected order: %s, %s", trending[0].Signature, trending[1].Signature)
+	}
+	if trending[0].Emerging || !trending[1].Emerging {
+		t.Errorf("expected only the new deck to be emerging")
+	}
+	if trending[0].RecentUsage != 10 || trending[0].BaselineUsage != 2 {
+		t.Errorf("usage = %v/%v, want 10/2", trending[0].RecentUsage, trending[0].BaselineUsage)
+	}
+	if trending[1].RecentWinRate != 66.67 {
+		t.Errorf("RecentWinRate = %v, want 66.67", trending[1].RecentWinRate)
+	}
+}
+
+func TestRankTrendingDecks_EmptyWindow(t *testing.T) {
+	stats := []*models.DeckWindowStats{
+		{Signature: "deck", RecentGames: 10, RecentTotal: 10},
+	}
+
+	if trending := RankTrendingDecks(stats, TrendOptions{}); len(trending) != 0 {
+		t.Errorf("expected no trend without baseline games, got %+v", trending)
+	}
+}